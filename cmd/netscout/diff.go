@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/diff"
+	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+)
+
+// runDiff implements "netscout diff", comparing two json result files
+func runDiff(args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: netscout diff [options] old.json new.json\n\n")
+		fs.PrintDefaults()
+	}
+
+	var (
+		format     = fs.String("f", "text", "Output format (text, json)")
+		outputFile = fs.String("o", "", "Output file (default: stdout)")
+		exitCode   = fs.Bool("exit-code", false, "Exit with status 1 when the scans differ")
+	)
+
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 1
+	}
+
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: invalid output format: %s (valid: text, json)\n", *format)
+		return 1
+	}
+
+	older, err := result.LoadReport(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	newer, err := result.LoadReport(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	report := diff.Compare(older.Results, newer.Results)
+
+	var w io.Writer = os.Stdout
+	if *outputFile != "" {
+		f, err := os.Create(*outputFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to create output file: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if *format == "json" {
+		err = report.WriteJSON(w)
+	} else {
+		err = report.WriteText(w)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write diff: %v\n", err)
+		return 1
+	}
+
+	if *exitCode && !report.Empty() {
+		return 1
+	}
+	return 0
+}
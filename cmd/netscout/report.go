@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+)
+
+// runReport implements "netscout report", re-rendering a json result file
+func runReport(args []string) int {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: netscout report [options] results.json\n\n")
+		fs.PrintDefaults()
+	}
+
+	var (
+		format     = fs.String("f", "text", "Output format (text, json, csv, ndjson, xml, grep)")
+		outputFile = fs.String("o", "", "Output file (default: stdout)")
+	)
+
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 1
+	}
+
+	report, err := result.LoadReport(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if *outputFile != "" {
+		f, err := os.Create(*outputFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to create output file: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+
+	writer, err := result.NewWriter(w, *format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	for _, r := range report.Results {
+		if err := writer.WriteResult(r); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to write report: %v\n", err)
+			return 1
+		}
+	}
+
+	if err := writer.Flush(report.Summary); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write report: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
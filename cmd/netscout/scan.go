@@ -0,0 +1,377 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/config"
+	"github.com/JeffreyOmoakah/netscout.git/internal/discovery"
+	"github.com/JeffreyOmoakah/netscout.git/internal/scanner"
+)
+
+// runScan implements "netscout scan", the default command
+func runScan(args []string) int {
+	return scan("scan", args)
+}
+
+// runDiscover implements "netscout discover", which reports the live hosts
+// among the targets without port scanning them
+func runDiscover(args []string) int {
+	return scan("discover", args)
+}
+
+// scan parses the options shared by the scan and discover commands and
+// runs the scan
+func scan(name string, args []string) int {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: netscout %s -t <target> | -iL <file> [options]\n\n", name)
+		fs.PrintDefaults()
+	}
+
+	// Define CLI flags
+	var (
+		targets     = fs.String("t", "", "Target IP, CIDR range or hostname (e.g., 192.168.1.0/24)")
+		inputList   = fs.String("iL", "", "Read targets from a file, one or more per line (- = stdin)")
+		dnsServer   = fs.String("dns-server", "", "DNS server for hostname targets (e.g., 10.0.0.53 or 127.0.0.1:5353)")
+		ipVersion   = fs.String("ip-version", "both", "Addresses to scan for hostname targets (4, 6, both)")
+		sampleSize  = fs.Uint64("sample", 0, "Scan a random sample of N addresses from larger prefixes (0 = all)")
+		randomize   = fs.Bool("randomize", false, "Probe hosts and ports in a seeded random order")
+		seed        = fs.Uint64("seed", 0, "Seed for -randomize and -sample (0 = random, printed with -v)")
+		scopeFile   = fs.String("scope", "", "Scope file listing the networks and hostnames the scan may touch")
+		exclude     = fs.String("exclude", "", "Addresses, CIDR ranges or hostnames never to probe (comma-separated)")
+		excludeFile = fs.String("exclude-file", "", "File of addresses, CIDR ranges or hostnames never to probe")
+		allowResv   = fs.Bool("allow-reserved", false, "Allow probing multicast, broadcast and reserved ranges")
+		skipDisc    = fs.Bool("Pn", false, "Skip host discovery and treat every target as alive")
+		discOnly    = fs.Bool("sn", false, "Only discover live hosts, without port scanning them")
+		pingPorts   = fs.String("PS", discovery.DefaultPorts, "TCP ports pinged during host discovery")
+		ports       = fs.String("p", "80,443", "Ports to scan (e.g., 80,443, 1-1024, top100, ssh,http, T:80,U:53, 1-1024,!25)")
+		allPorts    = fs.Bool("p-", false, "Scan every port (same as -p 1-65535)")
+		workers     = fs.Int("w", 100, "Number of concurrent workers (SYN scans: probes awaiting a reply)")
+		scanType    = fs.String("scan", "tcp", "Scan type (tcp, syn, udp)")
+		synScan     = fs.Bool("sS", false, "SYN (half-open) scan, same as -scan syn (Linux, requires root or CAP_NET_RAW)")
+		timeout     = fs.Duration("timeout", 2*time.Second, "Connection timeout (TCP: until a host's round-trip time is measured)")
+		minTimeout  = fs.Duration("min-timeout", config.DefaultMinTimeout, "Lower bound of timeouts adapted to a host's round-trip time")
+		maxTimeout  = fs.Duration("max-timeout", config.DefaultMaxTimeout, "Upper bound of timeouts adapted to a host's round-trip time")
+		retries     = fs.Int("retries", 1, "Extra attempts for probes that time out or fail locally (0-10)")
+		backoff     = fs.Duration("retry-backoff", 250*time.Millisecond, "Delay before the first retry, doubled for each further one")
+		banner      = fs.Bool("banner", false, "Grab service banners from open TCP ports")
+		bannerWait  = fs.Duration("banner-timeout", 2*time.Second, "How long to wait for a banner")
+		bannerProbe = fs.String("banner-probe", "", `String sent to silent services (supports \r, \n, \xNN escapes)`)
+		bannerSize  = fs.Int("banner-size", 256, "Maximum banner bytes to keep")
+		serviceScan = fs.Bool("sV", false, "Detect service and version on open ports")
+		probeDB     = fs.String("probe-db", "", "Service probe database (default: embedded)")
+		intensity   = fs.Int("version-intensity", 7, "Service probe intensity (0-9)")
+		tlsInspect  = fs.Bool("tls", false, "Inspect TLS certificates and parameters on open ports")
+		tlsAll      = fs.Bool("tls-all", false, "Attempt a TLS handshake on every open port")
+		tlsVersions = fs.Bool("tls-versions", false, "Enumerate accepted TLS protocol versions")
+		tlsExpiring = fs.Duration("tls-expiring", 0, "Only report certificates expiring within this duration (e.g. 720h)")
+		httpProbe   = fs.Bool("http", false, "Probe open ports for HTTP(S) status, title, headers and favicon hash")
+		rateLimit   = fs.Int("rate", 0, "Rate limit (requests per second, 0 = unlimited)")
+		outputFile  = fs.String("o", "", "Output file (default: stdout)")
+		outputFmt   = fs.String("f", "text", "Output format (text, json, csv, ndjson, xml, grep)")
+		outputAll   = fs.String("oA", "", "Also write text, grep, json and xml output to <basename>.{txt,gnmap,json,xml}")
+		stream      = fs.Bool("stream", false, "Write each result as it arrives (text, csv, ndjson)")
+		dropClosed  = fs.Bool("drop-closed", false, "Keep only open and filtered results in memory")
+		checkpoint  = fs.String("checkpoint", "", "Periodically save scan progress to this file")
+		cpInterval  = fs.Duration("checkpoint-interval", 30*time.Second, "How often to save a checkpoint")
+		resume      = fs.String("resume", "", "Resume an interrupted scan from a checkpoint file")
+		configFile  = fs.String("config", config.DefaultFile(), "Configuration file (TOML)")
+		profile     = fs.String("profile", "", "Named scan profile from the configuration file (built-in: quick, full-tcp, web)")
+		dumpConfig  = fs.Bool("dump-config", false, "Print the effective configuration and exit")
+		showVersion = fs.Bool("version", false, "Show version")
+		verbose     = fs.Bool("v", false, "Verbose output")
+	)
+
+	fs.Parse(args)
+
+	// Handle version flag
+	if *showVersion {
+		fmt.Printf("NETscout v%s\n", version)
+		return 0
+	}
+
+	probe, err := unescape(*bannerProbe)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid -banner-probe: %v\n", err)
+		return 1
+	}
+
+	// Build configuration from the flags
+	flagCfg := &config.Config{
+		DNSServer:          *dnsServer,
+		IPVersion:          *ipVersion,
+		Targets:            parseTargets(*targets),
+		InputList:          *inputList,
+		SampleSize:         *sampleSize,
+		Randomize:          *randomize,
+		Seed:               *seed,
+		ScopeFile:          *scopeFile,
+		Exclude:            parseTargets(*exclude),
+		ExcludeFile:        *excludeFile,
+		AllowReserved:      *allowResv,
+		SkipDiscovery:      *skipDisc,
+		DiscoveryOnly:      *discOnly,
+		DiscoveryPorts:     *pingPorts,
+		Ports:              *ports,
+		Workers:            *workers,
+		ScanType:           *scanType,
+		Timeout:            *timeout,
+		MinTimeout:         *minTimeout,
+		MaxTimeout:         *maxTimeout,
+		Retries:            *retries,
+		RetryBackoff:       *backoff,
+		BannerGrab:         *banner,
+		BannerTimeout:      *bannerWait,
+		BannerProbe:        probe,
+		BannerSize:         *bannerSize,
+		ServiceDetection:   *serviceScan,
+		ProbeDatabase:      *probeDB,
+		VersionIntensity:   *intensity,
+		TLSInspect:         *tlsInspect,
+		TLSAllPorts:        *tlsAll,
+		TLSVersions:        *tlsVersions,
+		TLSExpiringWithin:  *tlsExpiring,
+		HTTPProbe:          *httpProbe,
+		RateLimit:          *rateLimit,
+		OutputFile:         *outputFile,
+		OutputFormat:       *outputFmt,
+		OutputBase:         *outputAll,
+		StreamOutput:       *stream,
+		DropClosed:         *dropClosed,
+		CheckpointFile:     *checkpoint,
+		CheckpointInterval: *cpInterval,
+		Verbose:            *verbose,
+	}
+
+	if *allPorts {
+		flagCfg.Ports = "-"
+	}
+	if *synScan {
+		flagCfg.ScanType = "syn"
+	}
+
+	// Settings come from the config file, then the environment, then any
+	// flags given explicitly on the command line
+	cfg := new(config.Config)
+	*cfg = *flagCfg
+	if err := cfg.LoadFile(*configFile, *profile); err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+	if err := cfg.ApplyEnv(); err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+	fs.Visit(func(f *flag.Flag) {
+		if key, ok := scanFlagKeys[f.Name]; ok {
+			cfg.CopyFrom(flagCfg, key)
+		}
+	})
+	cfg.TLSInspect = cfg.TLSInspect || cfg.TLSAllPorts || cfg.TLSVersions || cfg.TLSExpiringWithin > 0
+	if name == "discover" {
+		cfg.DiscoveryOnly = true
+	}
+
+	if *dumpConfig {
+		if err := cfg.Dump(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	// Validate required arguments
+	if len(cfg.Targets) == 0 && cfg.InputList == "" && *resume == "" {
+		fmt.Fprintf(os.Stderr, "Error: target (-t or -iL) is required\n\n")
+		fs.Usage()
+		return 1
+	}
+
+	// A resumed scan runs with the configuration it was started with
+	var cp *scanner.Checkpoint
+	if *resume != "" {
+		cp, err = scanner.LoadCheckpoint(*resume)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		cfg = cp.Config
+		cfg.Verbose = cfg.Verbose || *verbose
+		if cfg.CheckpointFile == "" {
+			cfg.CheckpointFile = *resume
+			cfg.CheckpointInterval = *cpInterval
+		}
+	}
+
+	// Validate configuration
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+
+	// Setup context with cancellation
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle graceful shutdown on SIGINT/SIGTERM
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-sigChan
+		fmt.Fprintln(os.Stderr, "\nReceived interrupt signal, shutting down gracefully...")
+		cancel()
+	}()
+
+	// Create scanner instance; resolving hostname targets can be interrupted
+	s, err := scanner.New(ctx, cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create scanner: %v\n", err)
+		return 1
+	}
+
+	if cp != nil {
+		if err := s.Restore(cp); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to resume scan: %v\n", err)
+			return 1
+		}
+	}
+
+	// Print scan info
+	if cfg.Verbose {
+		fmt.Fprintf(os.Stderr, "Starting NETscout v%s\n", version)
+		if cp != nil {
+			fmt.Fprintf(os.Stderr, "Resuming from: %s (%d tasks done)\n", *resume, cp.Position+uint64(len(cp.Completed)))
+		}
+		if len(cfg.Targets) > 0 {
+			fmt.Fprintf(os.Stderr, "Targets: %s\n", strings.Join(cfg.Targets, ","))
+		}
+		if cfg.InputList != "" {
+			fmt.Fprintf(os.Stderr, "Target list: %s\n", cfg.InputList)
+		}
+		if !cfg.DiscoveryOnly {
+			fmt.Fprintf(os.Stderr, "Ports: %s\n", cfg.Ports)
+		}
+		if cfg.SkipDiscovery {
+			fmt.Fprintf(os.Stderr, "Host discovery: skipped\n")
+		} else {
+			fmt.Fprintf(os.Stderr, "Discovery ports: %s\n", cfg.DiscoveryPorts)
+		}
+		fmt.Fprintf(os.Stderr, "Workers: %d\n", cfg.Workers)
+		fmt.Fprintf(os.Stderr, "Scan type: %s\n", cfg.ScanType)
+		fmt.Fprintf(os.Stderr, "Timeout: %v\n", cfg.Timeout)
+		fmt.Fprintf(os.Stderr, "Retries: %d\n", cfg.Retries)
+		fmt.Fprintf(os.Stderr, "Seed: %d\n", s.GetSeed())
+		fmt.Fprintln(os.Stderr, "")
+	}
+
+	// Run the scan
+	if err := s.Scan(ctx); err != nil {
+		if err == context.Canceled {
+			fmt.Fprintln(os.Stderr, "Scan cancelled by user")
+			return 130 // Standard exit code for SIGINT
+		}
+		fmt.Fprintf(os.Stderr, "Scan failed: %v\n", err)
+		return 1
+	}
+
+	// Print summary if verbose
+	if cfg.Verbose {
+		summary := s.GetSummary()
+		fmt.Fprintf(os.Stderr, "\nScan completed:\n")
+		if !cfg.SkipDiscovery {
+			fmt.Fprintf(os.Stderr, "  Hosts up: %d\n", summary.HostsUp)
+			fmt.Fprintf(os.Stderr, "  Hosts down: %d\n", summary.HostsDown)
+		}
+		if !cfg.DiscoveryOnly {
+			fmt.Fprintf(os.Stderr, "  Total scanned: %d\n", summary.TotalScanned)
+			fmt.Fprintf(os.Stderr, "  Open ports: %d\n", summary.OpenPorts)
+			fmt.Fprintf(os.Stderr, "  Closed ports: %d\n", summary.ClosedPorts)
+			fmt.Fprintf(os.Stderr, "  Filtered: %d\n", summary.Filtered)
+			if summary.OpenFiltered > 0 {
+				fmt.Fprintf(os.Stderr, "  Open|filtered: %d\n", summary.OpenFiltered)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "  Duration: %v\n", summary.Duration)
+	}
+
+	return 0
+}
+
+// scanFlagKeys maps scan flags to the configuration settings they set
+var scanFlagKeys = map[string]string{
+	"t":                   "targets",
+	"iL":                  "input_list",
+	"dns-server":          "dns_server",
+	"ip-version":          "ip_version",
+	"sample":              "sample_size",
+	"randomize":           "randomize",
+	"seed":                "seed",
+	"scope":               "scope_file",
+	"exclude":             "exclude",
+	"exclude-file":        "exclude_file",
+	"allow-reserved":      "allow_reserved",
+	"Pn":                  "skip_discovery",
+	"sn":                  "discovery_only",
+	"PS":                  "discovery_ports",
+	"p":                   "ports",
+	"p-":                  "ports",
+	"w":                   "workers",
+	"scan":                "scan_type",
+	"sS":                  "scan_type",
+	"timeout":             "timeout",
+	"min-timeout":         "min_timeout",
+	"max-timeout":         "max_timeout",
+	"retries":             "retries",
+	"retry-backoff":       "retry_backoff",
+	"banner":              "banner_grab",
+	"banner-timeout":      "banner_timeout",
+	"banner-probe":        "banner_probe",
+	"banner-size":         "banner_size",
+	"sV":                  "service_detection",
+	"probe-db":            "probe_database",
+	"version-intensity":   "version_intensity",
+	"tls":                 "tls_inspect",
+	"tls-all":             "tls_all_ports",
+	"tls-versions":        "tls_versions",
+	"tls-expiring":        "tls_expiring_within",
+	"http":                "http_probe",
+	"rate":                "rate_limit",
+	"o":                   "output_file",
+	"f":                   "output_format",
+	"oA":                  "output_base",
+	"stream":              "stream_output",
+	"drop-closed":         "drop_closed",
+	"checkpoint":          "checkpoint_file",
+	"checkpoint-interval": "checkpoint_interval",
+	"v":                   "verbose",
+}
+
+// parseTargets splits comma-separated targets
+func parseTargets(targets string) []string {
+	parts := strings.Split(targets, ",")
+	result := make([]string, 0, len(parts))
+	for _, p := range parts {
+		trimmed := strings.TrimSpace(p)
+		if trimmed != "" {
+			result = append(result, trimmed)
+		}
+	}
+	return result
+}
+
+// unescape interprets backslash escapes such as \r\n in a probe string
+func unescape(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	return strconv.Unquote(`"` + strings.ReplaceAll(s, `"`, `\"`) + `"`)
+}
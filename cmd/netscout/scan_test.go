@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/xml"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+)
+
+func TestScanOutputAll(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	port := strconv.Itoa(ln.Addr().(*net.TCPAddr).Port)
+
+	tests := []struct {
+		name  string
+		flags []string
+	}{
+		{"buffered", []string{"-f", "json"}},
+		{"streamed", []string{"-f", "ndjson", "-stream"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			base := filepath.Join(dir, "scan")
+			args := append([]string{
+				"-config", "", "-t", "127.0.0.1", "-Pn", "-p", port,
+				"-o", filepath.Join(dir, "main.out"), "-oA", base,
+			}, tt.flags...)
+
+			if code := runScan(args); code != 0 {
+				t.Fatalf("runScan exited with %d", code)
+			}
+
+			if data := read(t, filepath.Join(dir, "main.out")); !strings.Contains(data, port) {
+				t.Errorf("main output lacks the open port:\n%s", data)
+			}
+
+			if data := read(t, base+".txt"); data != "127.0.0.1:"+port+" - open\n" {
+				t.Errorf("text output = %q", data)
+			}
+
+			gnmap := strings.Split(strings.TrimSpace(read(t, base+".gnmap")), "\n")
+			if len(gnmap) != 3 || gnmap[1] != "Host: 127.0.0.1 ()\tPorts: "+port+"/open/tcp/////" {
+				t.Errorf("grep output = %q", gnmap)
+			}
+
+			report, err := result.LoadReport(base + ".json")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(report.Results) != 1 || report.Results[0].Status != result.StatusOpen {
+				t.Errorf("json results = %+v", report.Results)
+			}
+
+			var run struct {
+				Hosts []struct {
+					Ports []struct {
+						PortID int `xml:"portid,attr"`
+						State  struct {
+							State string `xml:"state,attr"`
+						} `xml:"state"`
+					} `xml:"ports>port"`
+				} `xml:"host"`
+			}
+			if err := xml.Unmarshal([]byte(read(t, base+".xml")), &run); err != nil {
+				t.Fatal(err)
+			}
+			if len(run.Hosts) != 1 || len(run.Hosts[0].Ports) != 1 || run.Hosts[0].Ports[0].State.State != "open" {
+				t.Errorf("xml hosts = %+v", run.Hosts)
+			}
+		})
+	}
+}
+
+// read returns a file's contents
+func read(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+	"github.com/JeffreyOmoakah/netscout.git/internal/server"
+)
+
+// runServe implements "netscout serve", exposing a json result file over HTTP
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: netscout serve [options] results.json\n\n")
+		fs.PrintDefaults()
+	}
+
+	addr := fs.String("addr", "127.0.0.1:8080", "Address to listen on")
+
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 1
+	}
+
+	report, err := result.LoadReport(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           server.New(report),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	fmt.Fprintf(os.Stderr, "Serving %s (%d results) on http://%s\n", fs.Arg(0), len(report.Results), *addr)
+	if err := srv.ListenAndServe(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
@@ -5,38 +5,183 @@ import (
 	"time"
 )
 
+// Default bounds of the timeouts TCP scans adapt to each host
+const (
+	DefaultMinTimeout = 100 * time.Millisecond
+	DefaultMaxTimeout = 10 * time.Second
+)
+
+// Config holds the settings for a scan. Each field's config tag names the
+// key used for it in configuration files and NETSCOUT_* environment
+// variables.
 type Config struct {
-	// Targets is a list of IP addresses or CIDR ranges to scan
-	Targets []string
+	// Targets is a list of IP addresses, CIDR ranges or hostnames to scan
+	Targets []string `config:"targets"`
+
+	// InputList is a file of targets, one or more per line ("-" = stdin),
+	// read as the scan runs rather than up front
+	InputList string `config:"input_list"`
+
+	// DNSServer is the DNS server used to resolve hostnames (empty = system resolver)
+	DNSServer string `config:"dns_server"`
+
+	// IPVersion selects which addresses hostnames resolve to (4, 6, both)
+	IPVersion string `config:"ip_version"`
+
+	// SampleSize scans a random sample of this many addresses from larger
+	// prefixes instead of walking them (0 = walk every address)
+	SampleSize uint64 `config:"sample_size"`
+
+	// Randomize probes the host×port space in a seeded pseudo-random order
+	Randomize bool `config:"randomize"`
+
+	// Seed drives randomized ordering and sampling (0 = pick one at random)
+	Seed uint64 `config:"seed"`
+
+	// ScopeFile lists the networks and hostnames the scan may touch, plus
+	// denied ranges (empty = anything not denied)
+	ScopeFile string `config:"scope_file"`
+
+	// Exclude lists addresses, CIDR ranges and hostnames never to probe
+	Exclude []string `config:"exclude"`
+
+	// ExcludeFile lists further exclusions, one per line
+	ExcludeFile string `config:"exclude_file"`
+
+	// AllowReserved permits probing multicast, broadcast and reserved ranges
+	AllowReserved bool `config:"allow_reserved"`
+
+	// SkipDiscovery treats every target as alive instead of first checking
+	// which hosts answer
+	SkipDiscovery bool `config:"skip_discovery"`
+
+	// DiscoveryOnly reports the live hosts without port scanning them
+	DiscoveryOnly bool `config:"discovery_only"`
+
+	// DiscoveryPorts are the TCP ports pinged during host discovery
+	DiscoveryPorts string `config:"discovery_ports"`
 
 	// Ports is a string representation of ports to scan (e.g., "80,443,8000-9000")
-	Ports string
+	Ports string `config:"ports"`
 
 	// Workers is the number of concurrent scanner workers
-	Workers int
+	Workers int `config:"workers"`
+
+	// ScanType selects how each task is probed (tcp connect, syn half-open
+	// over raw sockets, udp)
+	ScanType string `config:"scan_type"`
+
+	// Timeout is the connection timeout duration; TCP scans use it until a
+	// host's round-trip time has been measured
+	Timeout time.Duration `config:"timeout"`
+
+	// MinTimeout and MaxTimeout bound the timeouts TCP scans derive from
+	// each host's measured round-trip time, the initial Timeout included
+	MinTimeout time.Duration `config:"min_timeout"`
+	MaxTimeout time.Duration `config:"max_timeout"`
+
+	// Retries is how many more times a probe that timed out or failed
+	// locally is tried
+	Retries int `config:"retries"`
+
+	// RetryBackoff is the delay before the first retry, doubled for each
+	// further one
+	RetryBackoff time.Duration `config:"retry_backoff"`
+
+	// BannerGrab enables reading service banners from open TCP ports
+	BannerGrab bool `config:"banner_grab"`
 
-	// Timeout is the connection timeout duration
-	Timeout time.Duration
+	// BannerTimeout is how long to wait for a banner after connecting
+	BannerTimeout time.Duration `config:"banner_timeout"`
+
+	// BannerProbe is sent to services that do not greet first (empty = passive)
+	BannerProbe string `config:"banner_probe"`
+
+	// BannerSize is the maximum number of banner bytes kept per port
+	BannerSize int `config:"banner_size"`
+
+	// ServiceDetection enables probe-based service and version fingerprinting
+	ServiceDetection bool `config:"service_detection"`
+
+	// ProbeDatabase is a path to an nmap-service-probes style file (empty = embedded)
+	ProbeDatabase string `config:"probe_database"`
+
+	// VersionIntensity (0-9) limits which probes are tried on unregistered ports
+	VersionIntensity int `config:"version_intensity"`
+
+	// TLSInspect enables TLS handshakes and certificate inspection on open ports
+	TLSInspect bool `config:"tls_inspect"`
+
+	// TLSAllPorts tries a handshake on every open port, not just known TLS ports
+	TLSAllPorts bool `config:"tls_all_ports"`
+
+	// TLSVersions enumerates which TLS protocol versions each server accepts
+	TLSVersions bool `config:"tls_versions"`
+
+	// TLSExpiringWithin keeps only results whose certificate expires within
+	// this duration (0 = no filtering)
+	TLSExpiringWithin time.Duration `config:"tls_expiring_within"`
+
+	// HTTPProbe requests the root page of open ports and records the response
+	HTTPProbe bool `config:"http_probe"`
 
 	// RateLimit is the maximum requests per second (0 = unlimited)
-	RateLimit int
+	RateLimit int `config:"rate_limit"`
 
 	// OutputFile is the path to write results (empty = stdout)
-	OutputFile string
+	OutputFile string `config:"output_file"`
+
+	// OutputFormat is the format for results (text, json, csv, ndjson, xml, grep)
+	OutputFormat string `config:"output_format"`
 
-	// OutputFormat is the format for results (text, json, csv)
-	OutputFormat string
+	// OutputBase writes text, grep, json and xml output to files named
+	// after it, alongside the main output (empty = disabled)
+	OutputBase string `config:"output_base"`
+
+	// StreamOutput writes each result as it arrives instead of at the end
+	StreamOutput bool `config:"stream_output"`
+
+	// DropClosed keeps only open and filtered results in memory
+	DropClosed bool `config:"drop_closed"`
+
+	// CheckpointFile is where scan progress is periodically saved (empty = disabled)
+	CheckpointFile string `config:"checkpoint_file"`
+
+	// CheckpointInterval is how often a checkpoint is written
+	CheckpointInterval time.Duration `config:"checkpoint_interval"`
 
 	// Verbose enables detailed logging
-	Verbose bool
+	Verbose bool `config:"verbose"`
 }
 
 // Validate checks if the configuration is valid
 func (c *Config) Validate() error {
-	if len(c.Targets) == 0 {
+	if len(c.Targets) == 0 && c.InputList == "" {
 		return fmt.Errorf("at least one target must be specified")
 	}
 
+	if c.InputList == "-" && c.CheckpointFile != "" {
+		return fmt.Errorf("checkpoints require targets that can be read again (not stdin)")
+	}
+
+	if c.DiscoveryOnly && c.SkipDiscovery {
+		return fmt.Errorf("discovery-only mode cannot skip host discovery")
+	}
+
+	if c.DiscoveryOnly && c.CheckpointFile != "" {
+		return fmt.Errorf("checkpoints are not supported in discovery-only mode")
+	}
+
+	validIPVersions := map[string]bool{
+		"4":    true,
+		"6":    true,
+		"both": true,
+	}
+
+	if !validIPVersions[c.IPVersion] {
+		return fmt.Errorf("invalid IP version: %s (valid: 4, 6, both)", c.IPVersion)
+	}
+
 	if c.Ports == "" {
 		return fmt.Errorf("ports must be specified")
 	}
@@ -49,6 +194,20 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("workers cannot exceed 10000 (too many goroutines)")
 	}
 
+	validScanTypes := map[string]bool{
+		"tcp": true,
+		"syn": true,
+		"udp": true,
+	}
+
+	if !validScanTypes[c.ScanType] {
+		return fmt.Errorf("invalid scan type: %s (valid: tcp, syn, udp)", c.ScanType)
+	}
+
+	if c.ScanType == "syn" && (c.BannerGrab || c.ServiceDetection || c.TLSInspect || c.HTTPProbe) {
+		return fmt.Errorf("SYN scans cannot grab banners or run service, TLS or HTTP probes (use -scan tcp)")
+	}
+
 	if c.Timeout < time.Millisecond {
 		return fmt.Errorf("timeout must be at least 1ms")
 	}
@@ -57,23 +216,97 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("timeout cannot exceed 5 minutes")
 	}
 
+	// UDP scans keep the fixed timeout, so only TCP scans use the bounds
+	if c.Protocol() == "tcp" {
+		if c.MinTimeout < time.Millisecond {
+			return fmt.Errorf("minimum timeout must be at least 1ms")
+		}
+
+		if c.MaxTimeout > 5*time.Minute {
+			return fmt.Errorf("maximum timeout cannot exceed 5 minutes")
+		}
+
+		if c.MinTimeout > c.MaxTimeout {
+			return fmt.Errorf("minimum timeout (%v) cannot exceed maximum timeout (%v)", c.MinTimeout, c.MaxTimeout)
+		}
+	}
+
+	if c.Retries < 0 || c.Retries > 10 {
+		return fmt.Errorf("retries must be between 0 and 10")
+	}
+
+	if c.RetryBackoff < 0 {
+		return fmt.Errorf("retry backoff cannot be negative")
+	}
+
+	if c.RetryBackoff > time.Minute {
+		return fmt.Errorf("retry backoff cannot exceed 1 minute")
+	}
+
+	if c.BannerGrab {
+		if c.BannerTimeout < time.Millisecond {
+			return fmt.Errorf("banner timeout must be at least 1ms")
+		}
+
+		if c.BannerSize < 1 || c.BannerSize > 65536 {
+			return fmt.Errorf("banner size must be between 1 and 65536 bytes")
+		}
+	}
+
+	if c.ServiceDetection && (c.VersionIntensity < 0 || c.VersionIntensity > 9) {
+		return fmt.Errorf("version intensity must be between 0 and 9")
+	}
+
+	if c.TLSExpiringWithin < 0 {
+		return fmt.Errorf("certificate expiry window cannot be negative")
+	}
+
+	if c.TLSExpiringWithin > 0 && !c.TLSInspect {
+		return fmt.Errorf("certificate expiry filtering requires TLS inspection")
+	}
+
 	if c.RateLimit < 0 {
 		return fmt.Errorf("rate limit cannot be negative")
 	}
 
+	if c.CheckpointFile != "" && c.CheckpointInterval < time.Second {
+		return fmt.Errorf("checkpoint interval must be at least 1s")
+	}
+
 	validFormats := map[string]bool{
-		"text": true,
-		"json": true,
-		"csv":  true,
+		"text":   true,
+		"json":   true,
+		"csv":    true,
+		"ndjson": true,
+		"xml":    true,
+		"grep":   true,
 	}
 
 	if !validFormats[c.OutputFormat] {
-		return fmt.Errorf("invalid output format: %s (valid: text, json, csv)", c.OutputFormat)
+		return fmt.Errorf("invalid output format: %s (valid: text, json, csv, ndjson, xml, grep)", c.OutputFormat)
+	}
+
+	documentFormats := map[string]bool{
+		"json": true,
+		"xml":  true,
+		"grep": true,
+	}
+
+	if c.StreamOutput && documentFormats[c.OutputFormat] {
+		return fmt.Errorf("%s output cannot be streamed (use text, csv or ndjson)", c.OutputFormat)
 	}
 
 	return nil
 }
 
+// Protocol returns the transport protocol the scan type probes
+func (c *Config) Protocol() string {
+	if c.ScanType == "udp" {
+		return "udp"
+	}
+	return "tcp"
+}
+
 // GetWorkerCount returns the configured number of workers
 func (c *Config) GetWorkerCount() int {
 	return c.Workers
@@ -87,4 +320,4 @@ func (c *Config) GetTimeout() time.Duration {
 // IsVerbose returns whether verbose mode is enabled
 func (c *Config) IsVerbose() bool {
 	return c.Verbose
-}
\ No newline at end of file
+}
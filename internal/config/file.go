@@ -0,0 +1,294 @@
+package config
+
+import (
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// envPrefix prefixes the environment variable for each setting, e.g.
+	// NETSCOUT_PORTS
+	envPrefix = "NETSCOUT_"
+
+	// profilePrefix prefixes the tables that define named profiles
+	profilePrefix = "profile."
+)
+
+// builtinProfiles are available even without a configuration file; a file
+// may redefine them
+//
+//go:embed profiles.toml
+var builtinProfiles []byte
+
+// DefaultFile returns the configuration file used when none is given: the
+// NETSCOUT_CONFIG environment variable, or netscout/config.toml in the
+// user's configuration directory if it exists
+func DefaultFile() string {
+	if path := os.Getenv(envPrefix + "CONFIG"); path != "" {
+		return path
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	path := filepath.Join(dir, "netscout", "config.toml")
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// Keys returns every setting's key in declaration order
+func Keys() []string {
+	t := reflect.TypeOf(Config{})
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if key := t.Field(i).Tag.Get("config"); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// field returns the struct field holding the setting for key
+func (c *Config) field(key string) (reflect.Value, bool) {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("config") == key {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// LoadFile applies the top-level settings of a configuration file, then
+// those of the named profile when profile is non-empty. Profiles not
+// defined by the file fall back to the built-in ones. An empty path
+// applies only the profile.
+func (c *Config) LoadFile(path, profile string) error {
+	builtin, err := parseTOML(builtinProfiles)
+	if err != nil {
+		return fmt.Errorf("invalid built-in profiles: %w", err)
+	}
+
+	doc := document{"": {}}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read config file: %w", err)
+		}
+		if doc, err = parseTOML(data); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	for table := range doc {
+		if table != "" && !strings.HasPrefix(table, profilePrefix) {
+			return fmt.Errorf("%s: unknown table [%s] (expected [%sname])", path, table, profilePrefix)
+		}
+	}
+
+	if err := c.apply(doc[""]); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	if profile == "" {
+		return nil
+	}
+
+	if settings, ok := doc[profilePrefix+profile]; ok {
+		if err := c.apply(settings); err != nil {
+			return fmt.Errorf("%s: profile %s: %w", path, profile, err)
+		}
+		return nil
+	}
+
+	if settings, ok := builtin[profilePrefix+profile]; ok {
+		return c.apply(settings)
+	}
+
+	return fmt.Errorf("unknown profile: %s (available: %s)", profile, strings.Join(profileNames(doc, builtin), ", "))
+}
+
+// profileNames lists the profiles defined across documents
+func profileNames(docs ...document) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, doc := range docs {
+		for table := range doc {
+			name, ok := strings.CutPrefix(table, profilePrefix)
+			if ok && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// apply assigns every setting of a configuration file table
+func (c *Config) apply(settings map[string]interface{}) error {
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := c.setValue(key, settings[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setValue assigns a parsed configuration file value to a setting
+func (c *Config) setValue(key string, value interface{}) error {
+	f, ok := c.field(key)
+	if !ok {
+		return fmt.Errorf("unknown setting: %s", key)
+	}
+
+	switch v := value.(type) {
+	case string:
+		return c.Set(key, v)
+	case bool:
+		if f.Kind() == reflect.Bool {
+			f.SetBool(v)
+			return nil
+		}
+	case int64:
+		switch f.Kind() {
+		case reflect.Int:
+			f.SetInt(v)
+			return nil
+		case reflect.Uint64:
+			if v >= 0 {
+				f.SetUint(uint64(v))
+				return nil
+			}
+		}
+	case []string:
+		if f.Kind() == reflect.Slice {
+			f.Set(reflect.ValueOf(v))
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid value for %s: %v", key, value)
+}
+
+// Set assigns a setting from its string form: durations such as "2s",
+// comma-separated lists, numbers and booleans
+func (c *Config) Set(key, value string) error {
+	f, ok := c.field(key)
+	if !ok {
+		return fmt.Errorf("unknown setting: %s", key)
+	}
+
+	var err error
+	switch f.Interface().(type) {
+	case time.Duration:
+		var d time.Duration
+		if d, err = time.ParseDuration(value); err == nil {
+			f.SetInt(int64(d))
+		}
+	case []string:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		f.Set(reflect.ValueOf(items))
+	case string:
+		f.SetString(value)
+	case bool:
+		var b bool
+		if b, err = strconv.ParseBool(value); err == nil {
+			f.SetBool(b)
+		}
+	case int:
+		var n int64
+		if n, err = strconv.ParseInt(value, 10, 0); err == nil {
+			f.SetInt(n)
+		}
+	case uint64:
+		var n uint64
+		if n, err = strconv.ParseUint(value, 10, 64); err == nil {
+			f.SetUint(n)
+		}
+	default:
+		return fmt.Errorf("setting %s cannot be assigned", key)
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid value for %s: %q", key, value)
+	}
+	return nil
+}
+
+// ApplyEnv overrides settings from NETSCOUT_<KEY> environment variables,
+// e.g. NETSCOUT_PORTS=22,80 or NETSCOUT_TIMEOUT=500ms
+func (c *Config) ApplyEnv() error {
+	for _, key := range Keys() {
+		name := envPrefix + strings.ToUpper(key)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := c.Set(key, value); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// CopyFrom copies the setting for key from another configuration
+func (c *Config) CopyFrom(other *Config, key string) {
+	dst, ok := c.field(key)
+	if !ok {
+		return
+	}
+	src, _ := other.field(key)
+	dst.Set(src)
+}
+
+// Dump writes every setting in configuration file syntax
+func (c *Config) Dump(w io.Writer) error {
+	for _, key := range Keys() {
+		f, _ := c.field(key)
+
+		var value string
+		switch v := f.Interface().(type) {
+		case time.Duration:
+			value = strconv.Quote(v.String())
+		case []string:
+			quoted := make([]string, len(v))
+			for i, item := range v {
+				quoted[i] = strconv.Quote(item)
+			}
+			value = "[" + strings.Join(quoted, ", ") + "]"
+		case string:
+			value = strconv.Quote(v)
+		default:
+			value = fmt.Sprint(v)
+		}
+
+		if _, err := fmt.Fprintf(w, "%s = %s\n", key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	data := `
+workers = 50
+timeout = "3s"
+
+[profile.quick]
+workers = 10
+
+[profile.office]
+targets = ["10.1.0.0/24"]
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		profile string
+		want    Config
+		wantErr bool
+	}{
+		{"top level", path, "", Config{Workers: 50, Timeout: 3 * time.Second}, false},
+		{"file profile", path, "office", Config{Workers: 50, Timeout: 3 * time.Second, Targets: []string{"10.1.0.0/24"}}, false},
+		{"file overrides built-in", path, "quick", Config{Workers: 10, Timeout: 3 * time.Second}, false},
+		{"built-in profile", "", "web", Config{
+			Ports:            "80,443,3000,5000,8000,8008,8080,8081,8443,8888,9443",
+			HTTPProbe:        true,
+			TLSInspect:       true,
+			ServiceDetection: true,
+		}, false},
+		{"unknown profile", path, "nope", Config{}, true},
+		{"missing file", filepath.Join(t.TempDir(), "none.toml"), "", Config{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			err := cfg.LoadFile(tt.path, tt.profile)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadFile error = %v, want error = %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(cfg, tt.want) {
+				t.Errorf("LoadFile = %+v, want %+v", cfg, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFileRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"unknown setting", "colour = true"},
+		{"unknown table", "[defaults]\nworkers = 1"},
+		{"wrong type", `workers = "many"`},
+		{"bad duration", `timeout = "soon"`},
+		{"negative count", "sample_size = -1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.toml")
+			if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			var cfg Config
+			if err := cfg.LoadFile(path, ""); err == nil {
+				t.Errorf("LoadFile accepted %q", tt.data)
+			}
+		})
+	}
+}
+
+func TestDumpRoundTrip(t *testing.T) {
+	want := Config{
+		Targets:    []string{"10.0.0.0/24", "db01.internal"},
+		Ports:      "T:80,U:53",
+		Workers:    200,
+		Timeout:    1500 * time.Millisecond,
+		SampleSize: 10,
+		Randomize:  true,
+	}
+
+	var buf bytes.Buffer
+	if err := want.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := parseTOML(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Dump output does not parse: %v\n%s", err, buf.String())
+	}
+	var got Config
+	if err := got.apply(doc[""]); err != nil {
+		t.Fatal(err)
+	}
+
+	// Dump writes empty lists, which load back as empty rather than nil
+	got.Exclude = nil
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// document is a parsed configuration file: the settings of each table,
+// keyed by table name ("" for the top level)
+type document map[string]map[string]interface{}
+
+// parseTOML parses the subset of TOML used by configuration files: tables,
+// comments, and key/value pairs whose values are strings, integers,
+// booleans or single-line arrays of strings
+func parseTOML(data []byte) (document, error) {
+	doc := document{"": {}}
+	table := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(stripComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("line %d: invalid table header: %s", lineNum, line)
+			}
+			table = strings.TrimSpace(line[1 : len(line)-1])
+			if table == "" {
+				return nil, fmt.Errorf("line %d: empty table name", lineNum)
+			}
+			if _, ok := doc[table]; ok {
+				return nil, fmt.Errorf("line %d: duplicate table [%s]", lineNum, table)
+			}
+			doc[table] = map[string]interface{}{}
+			continue
+		}
+
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNum)
+		}
+		key = strings.Trim(strings.TrimSpace(key), `"`)
+		if key == "" {
+			return nil, fmt.Errorf("line %d: missing key", lineNum)
+		}
+		if _, ok := doc[table][key]; ok {
+			return nil, fmt.Errorf("line %d: duplicate key %s", lineNum, key)
+		}
+
+		value, err := parseValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", lineNum, key, err)
+		}
+		doc[table][key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// parseValue parses a string, integer, boolean or array of strings
+func parseValue(raw string) (interface{}, error) {
+	switch {
+	case raw == "":
+		return nil, fmt.Errorf("missing value")
+	case raw == "true":
+		return true, nil
+	case raw == "false":
+		return false, nil
+	case raw[0] == '"' || raw[0] == '\'':
+		return parseString(raw)
+	case raw[0] == '[':
+		return parseArray(raw)
+	}
+
+	n, err := strconv.ParseInt(strings.ReplaceAll(raw, "_", ""), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value: %s", raw)
+	}
+	return n, nil
+}
+
+// parseString parses a basic ("...") or literal ('...') string
+func parseString(raw string) (string, error) {
+	if len(raw) < 2 || raw[len(raw)-1] != raw[0] {
+		return "", fmt.Errorf("unterminated string: %s", raw)
+	}
+	if raw[0] == '\'' {
+		return raw[1 : len(raw)-1], nil
+	}
+	s, err := strconv.Unquote(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid string: %s", raw)
+	}
+	return s, nil
+}
+
+// parseArray parses a single-line array of strings
+func parseArray(raw string) ([]string, error) {
+	if raw[len(raw)-1] != ']' {
+		return nil, fmt.Errorf("unterminated array: %s", raw)
+	}
+
+	items := make([]string, 0)
+	rest := strings.TrimSpace(raw[1 : len(raw)-1])
+	for rest != "" {
+		if rest[0] != '"' && rest[0] != '\'' {
+			return nil, fmt.Errorf("arrays may only hold strings: %s", raw)
+		}
+		end := closingQuote(rest)
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated string in array: %s", raw)
+		}
+		item, err := parseString(rest[:end+1])
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+
+		rest = strings.TrimSpace(rest[end+1:])
+		if rest != "" {
+			if rest[0] != ',' {
+				return nil, fmt.Errorf("expected , between array items: %s", raw)
+			}
+			rest = strings.TrimSpace(rest[1:])
+		}
+	}
+	return items, nil
+}
+
+// closingQuote returns the index of the quote ending the string s starts
+// with, or -1
+func closingQuote(s string) int {
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quote == '"':
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// stripComment removes a trailing # comment that is not inside a string
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0 && c == '\\' && quote == '"':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == 0 && c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
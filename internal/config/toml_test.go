@@ -0,0 +1,107 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    document
+		wantErr bool
+	}{
+		{
+			name: "top level values",
+			data: `
+ports = "22,80"
+workers = 1_000
+verbose = true
+targets = ["10.0.0.0/24", '192.168.1.1']
+`,
+			want: document{"": {
+				"ports":   "22,80",
+				"workers": int64(1000),
+				"verbose": true,
+				"targets": []string{"10.0.0.0/24", "192.168.1.1"},
+			}},
+		},
+		{
+			name: "tables",
+			data: `
+timeout = "2s"
+
+[profile.web]
+http_probe = true
+`,
+			want: document{
+				"":            {"timeout": "2s"},
+				"profile.web": {"http_probe": true},
+			},
+		},
+		{
+			name: "comments and quoting",
+			data: `
+# a comment
+ports = "80#443" # trailing comment
+output = 'C:\scans\out.json'
+banner_probe = "a\tb\"c"
+"quoted key" = false
+empty = []
+`,
+			want: document{"": {
+				"ports":        "80#443",
+				"output":       `C:\scans\out.json`,
+				"banner_probe": "a\tb\"c",
+				"quoted key":   false,
+				"empty":        []string{},
+			}},
+		},
+		{name: "missing value", data: "ports =", wantErr: true},
+		{name: "missing equals", data: "ports", wantErr: true},
+		{name: "missing key", data: `= "x"`, wantErr: true},
+		{name: "duplicate key", data: "workers = 1\nworkers = 2", wantErr: true},
+		{name: "duplicate table", data: "[a]\n[a]", wantErr: true},
+		{name: "empty table", data: "[ ]", wantErr: true},
+		{name: "array of tables", data: "[[hosts]]", wantErr: true},
+		{name: "unterminated table", data: "[profile", wantErr: true},
+		{name: "unterminated string", data: `ports = "80`, wantErr: true},
+		{name: "unterminated array", data: `targets = ["a"`, wantErr: true},
+		{name: "array of numbers", data: "ports = [80, 443]", wantErr: true},
+		{name: "missing comma", data: `targets = ["a" "b"]`, wantErr: true},
+		{name: "float", data: "rate = 1.5", wantErr: true},
+		{name: "bare word", data: "scan_type = tcp", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTOML([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTOML error = %v, want error = %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTOML = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripComment(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{`a = 1 # note`, `a = 1 `},
+		{`a = "#x" # note`, `a = "#x" `},
+		{`a = '#x'`, `a = '#x'`},
+		{`a = "\"#x"`, `a = "\"#x"`},
+		{`# whole line`, ``},
+	}
+
+	for _, tt := range tests {
+		if got := stripComment(tt.line); got != tt.want {
+			t.Errorf("stripComment(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
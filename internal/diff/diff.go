@@ -0,0 +1,293 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+)
+
+// statusMissing marks a port that does not appear in one of the scans
+const statusMissing result.Status = "missing"
+
+// Service identifies the software detected on a port
+type Service struct {
+	Name    string `json:"name,omitempty"`
+	Product string `json:"product,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// String renders the service as "name (product version)"
+func (s Service) String() string {
+	if s.Name == "" {
+		return "unknown"
+	}
+	if product := strings.TrimSpace(s.Product + " " + s.Version); product != "" {
+		return s.Name + " (" + product + ")"
+	}
+	return s.Name
+}
+
+// PortChange describes a port whose open state differs between scans
+type PortChange struct {
+	IP        string        `json:"ip"`
+	Hostname  string        `json:"hostname,omitempty"`
+	Port      int           `json:"port"`
+	Protocol  string        `json:"protocol"`
+	OldStatus result.Status `json:"old_status"`
+	NewStatus result.Status `json:"new_status"`
+	Service   Service       `json:"service"`
+}
+
+// ServiceChange describes an open port whose detected service changed
+type ServiceChange struct {
+	IP       string  `json:"ip"`
+	Hostname string  `json:"hostname,omitempty"`
+	Port     int     `json:"port"`
+	Protocol string  `json:"protocol"`
+	Old      Service `json:"old"`
+	New      Service `json:"new"`
+}
+
+// Report lists everything that changed between two scans
+type Report struct {
+	NewHosts       []string        `json:"new_hosts"`
+	GoneHosts      []string        `json:"gone_hosts"`
+	Opened         []PortChange    `json:"opened"`
+	Closed         []PortChange    `json:"closed"`
+	ServiceChanges []ServiceChange `json:"service_changes"`
+}
+
+// Empty reports whether the scans were equivalent
+func (r *Report) Empty() bool {
+	return len(r.NewHosts) == 0 && len(r.GoneHosts) == 0 &&
+		len(r.Opened) == 0 && len(r.Closed) == 0 && len(r.ServiceChanges) == 0
+}
+
+// portKey identifies a port on a host
+type portKey struct {
+	ip       string
+	protocol string
+	port     int
+}
+
+// Compare reports the differences between an older and a newer scan. A
+// host counts as present when any of its ports answered, open or closed,
+// or when it answered host discovery. A service change needs a service
+// detected by the older scan, so comparing a scan run without -sV against
+// one run with it reports none.
+func Compare(older, newer []*result.Result) *Report {
+	oldPorts, oldHosts := index(older)
+	newPorts, newHosts := index(newer)
+
+	report := &Report{
+		NewHosts:       make([]string, 0),
+		GoneHosts:      make([]string, 0),
+		Opened:         make([]PortChange, 0),
+		Closed:         make([]PortChange, 0),
+		ServiceChanges: make([]ServiceChange, 0),
+	}
+
+	for ip := range newHosts {
+		if !oldHosts[ip] {
+			report.NewHosts = append(report.NewHosts, ip)
+		}
+	}
+	for ip := range oldHosts {
+		if !newHosts[ip] {
+			report.GoneHosts = append(report.GoneHosts, ip)
+		}
+	}
+
+	for key, now := range newPorts {
+		before := oldPorts[key]
+		switch {
+		case now.Status == result.StatusOpen && !isOpen(before):
+			report.Opened = append(report.Opened, portChange(key, before, now))
+		case now.Status == result.StatusOpen && serviceOf(before) != (Service{}) && serviceOf(before) != serviceOf(now):
+			report.ServiceChanges = append(report.ServiceChanges, ServiceChange{
+				IP:       key.ip,
+				Hostname: now.Hostname,
+				Port:     key.port,
+				Protocol: key.protocol,
+				Old:      serviceOf(before),
+				New:      serviceOf(now),
+			})
+		}
+	}
+
+	for key, before := range oldPorts {
+		if before.Status == result.StatusOpen && !isOpen(newPorts[key]) {
+			report.Closed = append(report.Closed, portChange(key, before, newPorts[key]))
+		}
+	}
+
+	sort.Strings(report.NewHosts)
+	sort.Strings(report.GoneHosts)
+	sortPorts(report.Opened)
+	sortPorts(report.Closed)
+	sort.Slice(report.ServiceChanges, func(i, j int) bool {
+		a, b := report.ServiceChanges[i], report.ServiceChanges[j]
+		return less(portKey{a.IP, a.Protocol, a.Port}, portKey{b.IP, b.Protocol, b.Port})
+	})
+
+	return report
+}
+
+// index maps each port to its result and records which hosts answered
+func index(results []*result.Result) (map[portKey]*result.Result, map[string]bool) {
+	ports := make(map[portKey]*result.Result, len(results))
+	hosts := make(map[string]bool)
+
+	for _, r := range results {
+		if r.Status == result.StatusUp {
+			hosts[r.IP] = true
+			continue
+		}
+
+		ports[portKey{ip: r.IP, protocol: r.Protocol, port: r.Port}] = r
+		if r.Status == result.StatusOpen || r.Status == result.StatusClosed {
+			hosts[r.IP] = true
+		}
+	}
+
+	return ports, hosts
+}
+
+// portChange describes a port moving from one state to another
+func portChange(key portKey, before, now *result.Result) PortChange {
+	change := PortChange{
+		IP:        key.ip,
+		Port:      key.port,
+		Protocol:  key.protocol,
+		OldStatus: statusOf(before),
+		NewStatus: statusOf(now),
+	}
+
+	// Describe the port by the scan in which it was open
+	open := now
+	if !isOpen(now) {
+		open = before
+	}
+	change.Hostname = open.Hostname
+	change.Service = serviceOf(open)
+
+	return change
+}
+
+// isOpen reports whether a port was found open
+func isOpen(r *result.Result) bool {
+	return r != nil && r.Status == result.StatusOpen
+}
+
+// statusOf returns a port's status, or statusMissing if it was not scanned
+func statusOf(r *result.Result) result.Status {
+	if r == nil {
+		return statusMissing
+	}
+	return r.Status
+}
+
+// serviceOf returns the service detected on a port
+func serviceOf(r *result.Result) Service {
+	if r == nil {
+		return Service{}
+	}
+	return Service{Name: r.Service, Product: r.Product, Version: r.Version}
+}
+
+// sortPorts orders port changes by host, protocol and port
+func sortPorts(changes []PortChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		return less(portKey{a.IP, a.Protocol, a.Port}, portKey{b.IP, b.Protocol, b.Port})
+	})
+}
+
+// less orders port keys by IP, protocol and port
+func less(a, b portKey) bool {
+	if a.ip != b.ip {
+		return a.ip < b.ip
+	}
+	if a.protocol != b.protocol {
+		return a.protocol < b.protocol
+	}
+	return a.port < b.port
+}
+
+// WriteJSON writes the report as an indented JSON document
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteText writes the report in a human-readable form
+func (r *Report) WriteText(w io.Writer) error {
+	if r.Empty() {
+		_, err := fmt.Fprintln(w, "No changes")
+		return err
+	}
+
+	var b strings.Builder
+
+	if len(r.NewHosts) > 0 {
+		fmt.Fprintf(&b, "New hosts (%d):\n", len(r.NewHosts))
+		for _, ip := range r.NewHosts {
+			fmt.Fprintf(&b, "  + %s\n", ip)
+		}
+	}
+
+	if len(r.GoneHosts) > 0 {
+		fmt.Fprintf(&b, "Disappeared hosts (%d):\n", len(r.GoneHosts))
+		for _, ip := range r.GoneHosts {
+			fmt.Fprintf(&b, "  - %s\n", ip)
+		}
+	}
+
+	if len(r.Opened) > 0 {
+		fmt.Fprintf(&b, "Newly opened ports (%d):\n", len(r.Opened))
+		for _, c := range r.Opened {
+			fmt.Fprintf(&b, "  + %s (%s -> %s)%s\n", address(c.IP, c.Hostname, c.Port, c.Protocol), c.OldStatus, c.NewStatus, serviceSuffix(c.Service))
+		}
+	}
+
+	if len(r.Closed) > 0 {
+		fmt.Fprintf(&b, "Newly closed ports (%d):\n", len(r.Closed))
+		for _, c := range r.Closed {
+			fmt.Fprintf(&b, "  - %s (%s -> %s)%s\n", address(c.IP, c.Hostname, c.Port, c.Protocol), c.OldStatus, c.NewStatus, serviceSuffix(c.Service))
+		}
+	}
+
+	if len(r.ServiceChanges) > 0 {
+		fmt.Fprintf(&b, "Service changes (%d):\n", len(r.ServiceChanges))
+		for _, c := range r.ServiceChanges {
+			fmt.Fprintf(&b, "  ~ %s %s -> %s\n", address(c.IP, c.Hostname, c.Port, c.Protocol), c.Old, c.New)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// serviceSuffix renders a detected service after a port, if there is one
+func serviceSuffix(s Service) string {
+	if s.Name == "" {
+		return ""
+	}
+	return " " + s.String()
+}
+
+// address renders a port as "ip:port/protocol", adding the hostname if known
+func address(ip, hostname string, port int, protocol string) string {
+	addr := net.JoinHostPort(ip, strconv.Itoa(port)) + "/" + protocol
+	if hostname != "" {
+		addr += " (" + hostname + ")"
+	}
+	return addr
+}
@@ -0,0 +1,148 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+)
+
+// port builds a scan result for a TCP port
+func port(ip string, n int, status result.Status, service string) *result.Result {
+	return &result.Result{IP: ip, Port: n, Protocol: "tcp", Status: status, Service: service}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name         string
+		older, newer []*result.Result
+		want         *Report
+	}{
+		{
+			name:  "unchanged",
+			older: []*result.Result{port("10.0.0.1", 22, result.StatusOpen, "ssh")},
+			newer: []*result.Result{port("10.0.0.1", 22, result.StatusOpen, "ssh")},
+			want:  &Report{},
+		},
+		{
+			name:  "opened",
+			older: []*result.Result{port("10.0.0.1", 22, result.StatusOpen, ""), port("10.0.0.1", 80, result.StatusClosed, "")},
+			newer: []*result.Result{port("10.0.0.1", 22, result.StatusOpen, ""), port("10.0.0.1", 80, result.StatusOpen, "http"), port("10.0.0.1", 443, result.StatusOpen, "")},
+			want: &Report{Opened: []PortChange{
+				{IP: "10.0.0.1", Port: 80, Protocol: "tcp", OldStatus: result.StatusClosed, NewStatus: result.StatusOpen, Service: Service{Name: "http"}},
+				{IP: "10.0.0.1", Port: 443, Protocol: "tcp", OldStatus: statusMissing, NewStatus: result.StatusOpen},
+			}},
+		},
+		{
+			name:  "closed",
+			older: []*result.Result{port("10.0.0.1", 22, result.StatusOpen, "ssh"), port("10.0.0.1", 80, result.StatusOpen, "")},
+			newer: []*result.Result{port("10.0.0.1", 22, result.StatusFiltered, "")},
+			want: &Report{
+				// Nothing on the host answered the newer scan
+				GoneHosts: []string{"10.0.0.1"},
+				Closed: []PortChange{
+					{IP: "10.0.0.1", Port: 22, Protocol: "tcp", OldStatus: result.StatusOpen, NewStatus: result.StatusFiltered, Service: Service{Name: "ssh"}},
+					{IP: "10.0.0.1", Port: 80, Protocol: "tcp", OldStatus: result.StatusOpen, NewStatus: statusMissing},
+				},
+			},
+		},
+		{
+			name:  "protocols kept apart",
+			older: []*result.Result{port("10.0.0.1", 53, result.StatusOpen, "")},
+			newer: []*result.Result{{IP: "10.0.0.1", Port: 53, Protocol: "udp", Status: result.StatusOpen}},
+			want: &Report{
+				Opened: []PortChange{{IP: "10.0.0.1", Port: 53, Protocol: "udp", OldStatus: statusMissing, NewStatus: result.StatusOpen}},
+				Closed: []PortChange{{IP: "10.0.0.1", Port: 53, Protocol: "tcp", OldStatus: result.StatusOpen, NewStatus: statusMissing}},
+			},
+		},
+		{
+			name: "new and gone hosts",
+			older: []*result.Result{
+				port("10.0.0.1", 22, result.StatusClosed, ""),
+				port("10.0.0.2", 22, result.StatusFiltered, ""),
+			},
+			newer: []*result.Result{
+				port("10.0.0.2", 22, result.StatusClosed, ""),
+				{IP: "10.0.0.3", Protocol: "icmp", Status: result.StatusUp},
+			},
+			want: &Report{NewHosts: []string{"10.0.0.2", "10.0.0.3"}, GoneHosts: []string{"10.0.0.1"}},
+		},
+		{
+			name:  "service changed",
+			older: []*result.Result{{IP: "10.0.0.1", Port: 22, Protocol: "tcp", Status: result.StatusOpen, Service: "ssh", Product: "OpenSSH", Version: "8.9"}},
+			newer: []*result.Result{{IP: "10.0.0.1", Hostname: "db.test", Port: 22, Protocol: "tcp", Status: result.StatusOpen, Service: "ssh", Product: "OpenSSH", Version: "9.6"}},
+			want: &Report{ServiceChanges: []ServiceChange{{
+				IP: "10.0.0.1", Hostname: "db.test", Port: 22, Protocol: "tcp",
+				Old: Service{Name: "ssh", Product: "OpenSSH", Version: "8.9"},
+				New: Service{Name: "ssh", Product: "OpenSSH", Version: "9.6"},
+			}}},
+		},
+		{
+			// The older scan ran without service detection
+			name:  "service newly detected",
+			older: []*result.Result{port("10.0.0.1", 22, result.StatusOpen, "")},
+			newer: []*result.Result{port("10.0.0.1", 22, result.StatusOpen, "ssh")},
+			want:  &Report{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Compare(tt.older, tt.newer)
+			want := normalize(tt.want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Compare =\n%+v\nwant\n%+v", got, want)
+			}
+			if got.Empty() != tt.want.Empty() {
+				t.Errorf("Empty() = %v", got.Empty())
+			}
+		})
+	}
+}
+
+// normalize replaces a report's nil lists with the empty ones Compare makes
+func normalize(r *Report) *Report {
+	n := *r
+	if n.NewHosts == nil {
+		n.NewHosts = make([]string, 0)
+	}
+	if n.GoneHosts == nil {
+		n.GoneHosts = make([]string, 0)
+	}
+	if n.Opened == nil {
+		n.Opened = make([]PortChange, 0)
+	}
+	if n.Closed == nil {
+		n.Closed = make([]PortChange, 0)
+	}
+	if n.ServiceChanges == nil {
+		n.ServiceChanges = make([]ServiceChange, 0)
+	}
+	return &n
+}
+
+func TestWriteText(t *testing.T) {
+	older := []*result.Result{port("10.0.0.1", 22, result.StatusOpen, "ssh")}
+	newer := []*result.Result{port("10.0.0.1", 22, result.StatusClosed, ""), port("10.0.0.1", 80, result.StatusOpen, "")}
+
+	var b strings.Builder
+	if err := Compare(older, newer).WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := "Newly opened ports (1):\n" +
+		"  + 10.0.0.1:80/tcp (missing -> open)\n" +
+		"Newly closed ports (1):\n" +
+		"  - 10.0.0.1:22/tcp (open -> closed) ssh\n"
+	if b.String() != want {
+		t.Errorf("WriteText =\n%s\nwant\n%s", b.String(), want)
+	}
+
+	b.Reset()
+	if err := Compare(older, older).WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "No changes\n" {
+		t.Errorf("WriteText for equal scans = %q", b.String())
+	}
+}
@@ -0,0 +1,39 @@
+//go:build linux
+
+package discovery
+
+import (
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// atfCom marks a complete entry in the kernel's ARP table
+const atfCom = 0x2
+
+// arpTable is where the kernel publishes its ARP table
+var arpTable = "/proc/net/arp"
+
+// arpResolved reports whether the kernel's ARP table holds a complete entry
+// for addr, meaning the host answered an ARP request
+func arpResolved(addr netip.Addr) bool {
+	data, err := os.ReadFile(arpTable)
+	if err != nil {
+		return false
+	}
+
+	// Columns: IP address, HW type, Flags, HW address, Mask, Device
+	lines := strings.Split(string(data), "\n")
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[0] != addr.String() {
+			continue
+		}
+		flags, err := strconv.ParseUint(fields[2], 0, 32)
+		if err == nil && flags&atfCom != 0 && fields[3] != "00:00:00:00:00:00" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+//go:build linux
+
+package discovery
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestARPResolved(t *testing.T) {
+	table := `IP address       HW type     Flags       HW address            Mask     Device
+10.0.0.1         0x1         0x2         52:54:00:12:34:56     *        eth0
+10.0.0.2         0x1         0x0         00:00:00:00:00:00     *        eth0
+10.0.0.3         0x1         0x6         52:54:00:ab:cd:ef     *        eth0
+10.0.0.4         0x1         0x2         00:00:00:00:00:00     *        eth0
+10.0.0.5         0x1
+`
+	path := filepath.Join(t.TempDir(), "arp")
+	if err := os.WriteFile(path, []byte(table), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	defer func(old string) { arpTable = old }(arpTable)
+	arpTable = path
+
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"10.0.0.1", true},
+		{"10.0.0.2", false}, // incomplete: the host never answered
+		{"10.0.0.3", true},  // complete and permanent
+		{"10.0.0.4", false}, // no hardware address
+		{"10.0.0.5", false}, // truncated line
+		{"10.0.0.6", false}, // not in the table
+		{"10.0.0.10", false},
+	}
+
+	for _, tt := range tests {
+		if got := arpResolved(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("arpResolved(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+
+	arpTable = filepath.Join(t.TempDir(), "missing")
+	if arpResolved(netip.MustParseAddr("10.0.0.1")) {
+		t.Error("arpResolved without a table = true")
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package discovery
+
+import "net/netip"
+
+// arpResolved reports false: the ARP table is only read on Linux
+func arpResolved(addr netip.Addr) bool {
+	return false
+}
@@ -0,0 +1,164 @@
+package discovery
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+	"github.com/JeffreyOmoakah/netscout.git/internal/worker"
+)
+
+// DefaultPorts are the TCP ports pinged when no others are given: services
+// common enough that most live hosts answer on at least one of them
+const DefaultPorts = "22,80,443,445,3389"
+
+// Options controls how hosts are probed
+type Options struct {
+	// Ports are the TCP ports pinged on each host
+	Ports []int
+
+	// Timeout bounds how long a host has to answer any probe
+	Timeout time.Duration
+}
+
+// Reply describes the first probe a live host answered
+type Reply struct {
+	// Method is the kind of probe answered: tcp, icmp or arp
+	Method string
+
+	// Port is the TCP port that answered, for the tcp method
+	Port int
+
+	// Reason names the reply: syn-ack or reset for TCP, echo-reply for
+	// ICMP and arp-response for ARP
+	Reason string
+
+	// RTT is the time from sending the probes to the reply
+	RTT time.Duration
+}
+
+// Discoverer determines whether hosts are alive before they are port
+// scanned. Each host is pinged on a handful of TCP ports, where a completed
+// handshake or a reset both prove the host is up, and with an ICMP echo
+// where unprivileged ICMP sockets are permitted. Hosts on a directly
+// attached IPv4 network that answer neither are still counted as up when
+// they answered the ARP requests the probes caused.
+type Discoverer struct {
+	opts  Options
+	icmp  bool
+	local []netip.Prefix
+}
+
+// New creates a Discoverer, checking which probe methods are available
+func New(opts Options) *Discoverer {
+	return &Discoverer{
+		opts:  opts,
+		icmp:  icmpAvailable(),
+		local: localPrefixes(),
+	}
+}
+
+// Probe sends every probe to addr at once and reports the first reply, or
+// false if the host did not answer before the timeout
+func (d *Discoverer) Probe(ctx context.Context, addr netip.Addr) (Reply, bool) {
+	ctx, cancel := context.WithTimeout(ctx, d.opts.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	replies := make(chan Reply, len(d.opts.Ports)+1)
+	var wg sync.WaitGroup
+
+	for _, port := range d.opts.Ports {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if reason := pingTCP(ctx, addr, port); reason != "" {
+				replies <- Reply{Method: "tcp", Port: port, Reason: reason, RTT: time.Since(start)}
+			}
+		}()
+	}
+
+	if d.icmp {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if pingICMP(ctx, addr) {
+				replies <- Reply{Method: "icmp", Reason: result.ReasonEchoReply, RTT: time.Since(start)}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(replies)
+	}()
+
+	if reply, ok := <-replies; ok {
+		return reply, true
+	}
+
+	// The probes made the kernel resolve a neighbour's hardware address,
+	// which a host answers even when it filters everything else
+	if addr.Is4() && d.onLink(addr) && ctx.Err() != context.Canceled && arpResolved(addr) {
+		return Reply{Method: "arp", Reason: result.ReasonARPResponse, RTT: time.Since(start)}, true
+	}
+
+	return Reply{}, false
+}
+
+// pingTCP attempts a TCP connection and names the host's answer: syn-ack
+// for a completed handshake, reset for a refusal, or "" for none
+func pingTCP(ctx context.Context, addr netip.Addr, port int) string {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(addr.String(), strconv.Itoa(port)))
+	if err != nil {
+		if status, _ := worker.ClassifyError(err); status == result.StatusClosed {
+			return result.ReasonReset
+		}
+		return ""
+	}
+	conn.Close()
+	return result.ReasonSynAck
+}
+
+// onLink reports whether addr is on a network attached to this host
+func (d *Discoverer) onLink(addr netip.Addr) bool {
+	for _, prefix := range d.local {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// localPrefixes lists the IPv4 networks attached to this host's interfaces,
+// other than loopback
+func localPrefixes() []netip.Prefix {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+
+	var prefixes []netip.Prefix
+	for _, a := range addrs {
+		ipNet, ok := a.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		ip, ok := netip.AddrFromSlice(ipNet.IP)
+		if !ok {
+			continue
+		}
+		ip = ip.Unmap()
+		ones, _ := ipNet.Mask.Size()
+		if !ip.Is4() || ip.IsLoopback() || ones == 32 {
+			continue
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(ip, ones).Masked())
+	}
+	return prefixes
+}
@@ -0,0 +1,59 @@
+//go:build linux
+
+package discovery
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// silentPort returns a loopback port whose listener never accepts and has
+// a full queue, so the kernel drops further connection attempts unanswered
+func silentPort(t *testing.T) int {
+	t.Helper()
+
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_STREAM, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { syscall.Close(fd) })
+
+	if err := syscall.Bind(fd, &syscall.SockaddrInet4{Addr: [4]byte{127, 0, 0, 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Listen(fd, 0); err != nil {
+		t.Fatal(err)
+	}
+	sa, err := syscall.Getsockname(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := sa.(*syscall.SockaddrInet4).Port
+
+	// A zero backlog still queues one connection
+	conn, err := net.DialTimeout("tcp4", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return port
+}
+
+func TestProbeSilent(t *testing.T) {
+	port := silentPort(t)
+	d := &Discoverer{opts: Options{Ports: []int{port}, Timeout: 200 * time.Millisecond}}
+
+	start := time.Now()
+	if reply, live := d.Probe(context.Background(), netip.MustParseAddr("127.0.0.1")); live {
+		t.Fatalf("Probe = %+v, want no reply", reply)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("Probe gave up after %v, want the 200ms timeout", elapsed)
+	}
+}
@@ -0,0 +1,107 @@
+package discovery
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+)
+
+// loopbackPorts returns a loopback port with a listener on it and one
+// without
+func loopbackPorts(t *testing.T) (open, closed int) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	closedLn, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedLn.Close()
+
+	return ln.Addr().(*net.TCPAddr).Port, closedLn.Addr().(*net.TCPAddr).Port
+}
+
+func TestProbe(t *testing.T) {
+	open, closed := loopbackPorts(t)
+
+	tests := []struct {
+		name   string
+		addr   string
+		ports  []int
+		live   bool
+		port   int
+		reason string
+	}{
+		{"listening port", "127.0.0.1", []int{open}, true, open, result.ReasonSynAck},
+		{"closed port", "127.0.0.1", []int{closed}, true, closed, result.ReasonReset},
+		{"no probes", "127.0.0.1", nil, false, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Only TCP pings: ICMP and ARP depend on the host running the test
+			d := &Discoverer{opts: Options{Ports: tt.ports, Timeout: 300 * time.Millisecond}}
+
+			reply, live := d.Probe(context.Background(), netip.MustParseAddr(tt.addr))
+			if live != tt.live {
+				t.Fatalf("Probe live = %v, want %v", live, tt.live)
+			}
+			if !live {
+				return
+			}
+			if reply.Method != "tcp" || reply.Port != tt.port || reply.Reason != tt.reason {
+				t.Errorf("reply = %+v, want tcp/%d (%s)", reply, tt.port, tt.reason)
+			}
+			if reply.RTT <= 0 {
+				t.Errorf("RTT = %v, want a positive duration", reply.RTT)
+			}
+		})
+	}
+}
+
+func TestProbeFirstReply(t *testing.T) {
+	open, closed := loopbackPorts(t)
+	d := &Discoverer{opts: Options{Ports: []int{closed, open}, Timeout: time.Second}}
+
+	reply, live := d.Probe(context.Background(), netip.MustParseAddr("127.0.0.1"))
+	if !live || (reply.Port != open && reply.Port != closed) {
+		t.Errorf("Probe = %+v, %v, want a reply from one of the pinged ports", reply, live)
+	}
+}
+
+func TestOnLink(t *testing.T) {
+	d := &Discoverer{local: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/24")}}
+
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"10.0.0.7", true},
+		{"10.0.1.7", false},
+		{"192.0.2.1", false},
+	}
+
+	for _, tt := range tests {
+		if got := d.onLink(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("onLink(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+//go:build linux
+
+package discovery
+
+import (
+	"context"
+	"math/rand/v2"
+	"net"
+	"net/netip"
+	"os"
+	"syscall"
+	"time"
+)
+
+// icmpAvailable reports whether this process may open unprivileged ICMP
+// echo sockets, which the net.ipv4.ping_group_range sysctl controls
+func icmpAvailable() bool {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM, syscall.IPPROTO_ICMP)
+	if err != nil {
+		return false
+	}
+	syscall.Close(fd)
+	return true
+}
+
+// pingICMP sends an ICMP echo request to addr and reports whether a reply
+// arrived. The kernel fills in the identifier and checksum of messages on
+// unprivileged sockets and only delivers replies to our own requests.
+func pingICMP(ctx context.Context, addr netip.Addr) bool {
+	family, proto := syscall.AF_INET, syscall.IPPROTO_ICMP
+	request, reply := byte(8), byte(0)
+	var local syscall.Sockaddr = &syscall.SockaddrInet4{}
+	if addr.Is6() {
+		family, proto = syscall.AF_INET6, syscall.IPPROTO_ICMPV6
+		request, reply = 128, 129
+		local = &syscall.SockaddrInet6{}
+	}
+
+	fd, err := syscall.Socket(family, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, proto)
+	if err != nil {
+		return false
+	}
+	if err := syscall.Bind(fd, local); err != nil {
+		syscall.Close(fd)
+		return false
+	}
+
+	f := os.NewFile(uintptr(fd), "icmp")
+	conn, err := net.FilePacketConn(f)
+	f.Close()
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	seq := uint16(rand.Uint32())
+	msg := []byte{request, 0, 0, 0, 0, 0, byte(seq >> 8), byte(seq), 'n', 's'}
+	dst := &net.UDPAddr{IP: addr.AsSlice(), Zone: addr.Zone()}
+	if _, err := conn.WriteTo(msg, dst); err != nil {
+		return false
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			return false
+		}
+		src, ok := from.(*net.UDPAddr)
+		if !ok || n < 8 || buf[0] != reply {
+			continue
+		}
+		if srcAddr, ok := netip.AddrFromSlice(src.IP); !ok || srcAddr.Unmap() != addr.WithZone("") {
+			continue
+		}
+		if uint16(buf[6])<<8|uint16(buf[7]) == seq {
+			return true
+		}
+	}
+}
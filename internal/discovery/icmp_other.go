@@ -0,0 +1,19 @@
+//go:build !linux
+
+package discovery
+
+import (
+	"context"
+	"net/netip"
+)
+
+// icmpAvailable reports false: unprivileged ICMP sockets are only used on
+// Linux
+func icmpAvailable() bool {
+	return false
+}
+
+// pingICMP is never called where ICMP is unavailable
+func pingICMP(ctx context.Context, addr netip.Addr) bool {
+	return false
+}
@@ -0,0 +1,324 @@
+package fingerprint
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/parser"
+)
+
+//go:embed service-probes.txt
+var defaultDatabase string
+
+// Probe is a payload sent to a service together with the patterns used to
+// recognize the reply
+type Probe struct {
+	Protocol  string
+	Name      string
+	Payload   []byte
+	Ports     map[int]bool
+	Rarity    int
+	TotalWait time.Duration
+	Matches   []*Match
+}
+
+// Match recognizes a service from a probe reply and describes how to
+// extract product and version details from it
+type Match struct {
+	Service string
+	Pattern *regexp.Regexp
+	Soft    bool
+	Product string
+	Version string
+	CPE     string
+}
+
+// Database is a collection of probes in nmap-service-probes format
+type Database struct {
+	Probes []*Probe
+
+	// Skipped counts match lines whose patterns could not be compiled
+	Skipped int
+}
+
+// Default returns the probe database embedded in the binary
+func Default() (*Database, error) {
+	return Parse(strings.NewReader(defaultDatabase))
+}
+
+// LoadFile reads a probe database from disk
+func LoadFile(path string) (*Database, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open probe database: %w", err)
+	}
+	defer f.Close()
+
+	return Parse(f)
+}
+
+// Parse reads a probe database in nmap-service-probes format. The Probe,
+// match, softmatch, ports, rarity and totalwaitms directives are honored;
+// other directives are ignored. Patterns using Perl features unsupported
+// by Go's regexp package are skipped and counted in Skipped.
+func Parse(r io.Reader) (*Database, error) {
+	db := &Database{}
+	var current *Probe
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		directive, rest, _ := strings.Cut(line, " ")
+		rest = strings.TrimSpace(rest)
+
+		if directive == "Probe" {
+			probe, err := parseProbe(rest)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			db.Probes = append(db.Probes, probe)
+			current = probe
+			continue
+		}
+
+		if current == nil {
+			// Directives such as Exclude may precede the first probe
+			continue
+		}
+
+		switch directive {
+		case "match", "softmatch":
+			m, err := parseMatch(rest, directive == "softmatch")
+			if err != nil {
+				if _, ok := err.(*regexError); ok {
+					db.Skipped++
+					continue
+				}
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			current.Matches = append(current.Matches, m)
+
+		case "ports":
+			ports, err := parser.ParsePorts(rest, current.Protocol)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			for _, p := range ports {
+				current.Ports[p] = true
+			}
+
+		case "rarity":
+			rarity, err := strconv.Atoi(rest)
+			if err != nil || rarity < 1 || rarity > 9 {
+				return nil, fmt.Errorf("line %d: invalid rarity: %s", lineNum, rest)
+			}
+			current.Rarity = rarity
+
+		case "totalwaitms":
+			ms, err := strconv.Atoi(rest)
+			if err != nil || ms < 1 {
+				return nil, fmt.Errorf("line %d: invalid totalwaitms: %s", lineNum, rest)
+			}
+			current.TotalWait = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read probe database: %w", err)
+	}
+
+	if len(db.Probes) == 0 {
+		return nil, fmt.Errorf("probe database contains no probes")
+	}
+
+	return db, nil
+}
+
+// parseProbe parses the arguments of a Probe directive,
+// e.g. "TCP GetRequest q|GET / HTTP/1.0\r\n\r\n|"
+func parseProbe(spec string) (*Probe, error) {
+	fields := strings.SplitN(spec, " ", 3)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("invalid probe: %s", spec)
+	}
+
+	protocol := strings.ToLower(fields[0])
+	if protocol != "tcp" && protocol != "udp" {
+		return nil, fmt.Errorf("invalid probe protocol: %s", fields[0])
+	}
+
+	payload := fields[2]
+	if len(payload) < 3 || payload[0] != 'q' {
+		return nil, fmt.Errorf("invalid probe string: %s", payload)
+	}
+	body, _, err := delimited(payload[1:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid probe string: %w", err)
+	}
+
+	decoded, err := unescape(body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid probe string: %w", err)
+	}
+
+	return &Probe{
+		Protocol: protocol,
+		Name:     fields[1],
+		Payload:  decoded,
+		Ports:    make(map[int]bool),
+		Rarity:   1,
+	}, nil
+}
+
+// regexError reports a pattern that Go's regexp package cannot compile
+type regexError struct {
+	err error
+}
+
+func (e *regexError) Error() string {
+	return e.err.Error()
+}
+
+// parseMatch parses the arguments of a match or softmatch directive,
+// e.g. "ssh m|^SSH-([\d.]+)-OpenSSH_(\S+)| p/OpenSSH/ v/$2/"
+func parseMatch(spec string, soft bool) (*Match, error) {
+	service, rest, ok := strings.Cut(spec, " ")
+	if !ok || len(rest) < 3 || rest[0] != 'm' {
+		return nil, fmt.Errorf("invalid match: %s", spec)
+	}
+
+	pattern, rest, err := delimited(rest[1:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid match pattern: %w", err)
+	}
+
+	// Pattern flags follow the closing delimiter
+	flags := ""
+	for len(rest) > 0 && (rest[0] == 's' || rest[0] == 'i') {
+		flags += rest[:1]
+		rest = rest[1:]
+	}
+	if flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, &regexError{err: err}
+	}
+
+	m := &Match{
+		Service: service,
+		Pattern: re,
+		Soft:    soft,
+	}
+
+	// Version info fields: p/product/ v/version/ cpe:/cpe/ and others
+	for {
+		rest = strings.TrimSpace(rest)
+		if rest == "" {
+			break
+		}
+
+		var name string
+		if strings.HasPrefix(rest, "cpe:") {
+			name, rest = "cpe", rest[4:]
+		} else {
+			name, rest = rest[:1], rest[1:]
+		}
+
+		value, remaining, err := delimited(rest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s field in match: %w", name, err)
+		}
+		rest = strings.TrimLeft(remaining, "a")
+
+		switch name {
+		case "p":
+			m.Product = value
+		case "v":
+			m.Version = value
+		case "cpe":
+			// Only the first CPE is kept
+			if m.CPE == "" {
+				m.CPE = "cpe:/" + value
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// delimited splits "|body|rest" on the delimiter given by the first character
+func delimited(s string) (string, string, error) {
+	if s == "" {
+		return "", "", fmt.Errorf("missing delimiter")
+	}
+	delim := s[0]
+	end := strings.IndexByte(s[1:], delim)
+	if end < 0 {
+		return "", "", fmt.Errorf("unterminated field: %s", s)
+	}
+	return s[1 : end+1], s[end+2:], nil
+}
+
+// unescape decodes the C-style escapes used in probe strings
+func unescape(s string) ([]byte, error) {
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			out = append(out, s[i])
+			continue
+		}
+
+		i++
+		if i >= len(s) {
+			return nil, fmt.Errorf("trailing backslash")
+		}
+
+		switch s[i] {
+		case 'r':
+			out = append(out, '\r')
+		case 'n':
+			out = append(out, '\n')
+		case 't':
+			out = append(out, '\t')
+		case '0':
+			out = append(out, 0)
+		case 'a':
+			out = append(out, '\a')
+		case 'f':
+			out = append(out, '\f')
+		case 'v':
+			out = append(out, '\v')
+		case 'x':
+			if i+2 >= len(s) {
+				return nil, fmt.Errorf("truncated hex escape")
+			}
+			b, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+			if err != nil {
+				return nil, fmt.Errorf("invalid hex escape: \\x%s", s[i+1:i+3])
+			}
+			out = append(out, byte(b))
+			i += 2
+		default:
+			out = append(out, s[i])
+		}
+	}
+	return out, nil
+}
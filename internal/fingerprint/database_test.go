@@ -0,0 +1,277 @@
+package fingerprint
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDelimited(t *testing.T) {
+	tests := []struct {
+		in         string
+		body, rest string
+		wantErr    bool
+	}{
+		{"|GET /|", "GET /", "", false},
+		{"|a|b|", "a", "b|", false},
+		{"=x|y= p/z/", "x|y", " p/z/", false},
+		{"||", "", "", false},
+		{"|open", "", "", true},
+		{"", "", "", true},
+	}
+
+	for _, tt := range tests {
+		body, rest, err := delimited(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("delimited(%q) error = %v, want error = %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if body != tt.body || rest != tt.rest {
+			t.Errorf("delimited(%q) = %q, %q, want %q, %q", tt.in, body, rest, tt.body, tt.rest)
+		}
+	}
+}
+
+func TestUnescape(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []byte
+		wantErr bool
+	}{
+		{`GET / HTTP/1.0\r\n\r\n`, []byte("GET / HTTP/1.0\r\n\r\n"), false},
+		{`\0\x06\x01\xff`, []byte{0, 6, 1, 0xff}, false},
+		{`\t\a\f\v`, []byte{'\t', '\a', '\f', '\v'}, false},
+		{`a\|b\\`, []byte(`a|b\`), false},
+		{"", []byte{}, false},
+		{`abc\`, nil, true},
+		{`\x4`, nil, true},
+		{`\xzz`, nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := unescape(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("unescape(%q) error = %v, want error = %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("unescape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		soft    bool
+		want    Match
+		pattern string
+		wantErr bool
+		regex   bool
+	}{
+		{
+			name:    "version fields",
+			spec:    `ssh m|^SSH-([\d.]+)-OpenSSH_(\S+)| p/OpenSSH/ v/$2/ cpe:/a:openbsd:openssh:$2/`,
+			want:    Match{Service: "ssh", Product: "OpenSSH", Version: "$2", CPE: "cpe:/a:openbsd:openssh:$2"},
+			pattern: `^SSH-([\d.]+)-OpenSSH_(\S+)`,
+		},
+		{
+			name:    "flags",
+			spec:    `ftp m|^220.*ftp|si p/Generic/`,
+			want:    Match{Service: "ftp", Product: "Generic"},
+			pattern: `(?si)^220.*ftp`,
+		},
+		{
+			name:    "other delimiters",
+			spec:    `http m=^HTTP/1\.1 (\d+)= p|Some/Server| v=$1=`,
+			want:    Match{Service: "http", Product: "Some/Server", Version: "$1"},
+			pattern: `^HTTP/1\.1 (\d+)`,
+		},
+		{
+			name:    "first CPE kept",
+			spec:    `ssh m|^SSH| cpe:/a:one:one/a cpe:/o:two:two/a`,
+			want:    Match{Service: "ssh", CPE: "cpe:/a:one:one"},
+			pattern: `^SSH`,
+		},
+		{
+			name:    "unknown fields ignored",
+			spec:    `ssh m|^SSH| i/protocol 2.0/ h/$1/ o/Linux/`,
+			want:    Match{Service: "ssh"},
+			pattern: `^SSH`,
+		},
+		{
+			name:    "softmatch",
+			spec:    `ssh m|^SSH-|`,
+			soft:    true,
+			want:    Match{Service: "ssh", Soft: true},
+			pattern: `^SSH-`,
+		},
+		{name: "no pattern", spec: `ssh`, wantErr: true},
+		{name: "not a match pattern", spec: `ssh p|^SSH|`, wantErr: true},
+		{name: "unterminated pattern", spec: `ssh m|^SSH`, wantErr: true},
+		{name: "unterminated field", spec: `ssh m|^SSH| p/OpenSSH`, wantErr: true},
+		{name: "Perl lookahead", spec: `ssh m|^SSH(?=-2)|`, wantErr: true, regex: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := parseMatch(tt.spec, tt.soft)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseMatch error = %v, want error = %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if _, ok := err.(*regexError); ok != tt.regex {
+					t.Errorf("error %v is a pattern error = %v, want %v", err, ok, tt.regex)
+				}
+				return
+			}
+
+			if m.Pattern.String() != tt.pattern {
+				t.Errorf("pattern = %q, want %q", m.Pattern, tt.pattern)
+			}
+			got := *m
+			got.Pattern = nil
+			if got != tt.want {
+				t.Errorf("match = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// customDatabase exercises every directive Parse honors
+const customDatabase = `# comment
+Exclude T:9100-9107
+
+Probe TCP NULL q||
+totalwaitms 2000
+match ssh m|^SSH-2\.0-Custom_([\d.]+)| p/Custom sshd/ v/$1/
+match ssh m|^SSH(?!-1)|
+softmatch ssh m|^SSH-|
+
+Probe TCP Hello q|HELLO\r\n|
+rarity 4
+ports 7000-7002,T:7100
+match hello m|^HI ([^\r\n]+)\r\n| p/Hello daemon/ v/$P(1)/
+
+Probe UDP Ping q|\x01\x02|
+ports 7000
+fallback Hello
+match ping m|^\x02|
+`
+
+func TestParse(t *testing.T) {
+	db, err := Parse(strings.NewReader(customDatabase))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(db.Probes) != 3 {
+		t.Fatalf("got %d probes, want 3", len(db.Probes))
+	}
+	if db.Skipped != 1 {
+		t.Errorf("Skipped = %d, want 1 for the lookahead", db.Skipped)
+	}
+
+	tests := []struct {
+		protocol, name string
+		payload        string
+		ports          []int
+		rarity         int
+		wait           time.Duration
+		matches        int
+	}{
+		{"tcp", "NULL", "", nil, 1, 2 * time.Second, 2},
+		{"tcp", "Hello", "HELLO\r\n", []int{7000, 7001, 7002, 7100}, 4, 0, 1},
+		{"udp", "Ping", "\x01\x02", []int{7000}, 1, 0, 1},
+	}
+	for i, tt := range tests {
+		p := db.Probes[i]
+		if p.Protocol != tt.protocol || p.Name != tt.name || string(p.Payload) != tt.payload {
+			t.Errorf("probe %d = %s %s %q, want %s %s %q", i, p.Protocol, p.Name, p.Payload, tt.protocol, tt.name, tt.payload)
+		}
+		if len(p.Ports) != len(tt.ports) {
+			t.Errorf("probe %s has ports %v, want %v", p.Name, p.Ports, tt.ports)
+		}
+		for _, port := range tt.ports {
+			if !p.Ports[port] {
+				t.Errorf("probe %s lacks port %d", p.Name, port)
+			}
+		}
+		if p.Rarity != tt.rarity || p.TotalWait != tt.wait || len(p.Matches) != tt.matches {
+			t.Errorf("probe %s: rarity %d, wait %v, %d matches, want %d, %v, %d",
+				p.Name, p.Rarity, p.TotalWait, len(p.Matches), tt.rarity, tt.wait, tt.matches)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		db   string
+	}{
+		{"no probes", "# nothing\nmatch ssh m|^SSH|\n"},
+		{"bad protocol", "Probe SCTP Init q||\n"},
+		{"missing payload", "Probe TCP NULL\n"},
+		{"bad probe string", "Probe TCP NULL x||\n"},
+		{"bad escape", `Probe TCP NULL q|\x4|` + "\n"},
+		{"bad match", "Probe TCP NULL q||\nmatch ssh m|^SSH\n"},
+		{"bad ports", "Probe TCP NULL q||\nports 0-1\n"},
+		{"bad rarity", "Probe TCP NULL q||\nrarity 10\n"},
+		{"bad totalwaitms", "Probe TCP NULL q||\ntotalwaitms soon\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(strings.NewReader(tt.db)); err == nil {
+				t.Error("Parse succeeded")
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "probes")
+	if err := os.WriteFile(path, []byte(customDatabase), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(db.Probes) != 3 {
+		t.Errorf("got %d probes, want 3", len(db.Probes))
+	}
+
+	if _, err := LoadFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("LoadFile of a missing file succeeded")
+	}
+}
+
+func TestDefault(t *testing.T) {
+	db, err := Default()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Skipped != 0 {
+		t.Errorf("the embedded database has %d patterns Go cannot compile", db.Skipped)
+	}
+
+	names := make(map[string]bool)
+	for _, p := range db.Probes {
+		if names[p.Protocol+"/"+p.Name] {
+			t.Errorf("probe %s/%s defined twice", p.Protocol, p.Name)
+		}
+		names[p.Protocol+"/"+p.Name] = true
+		if len(p.Matches) == 0 {
+			t.Errorf("probe %s/%s has no matches", p.Protocol, p.Name)
+		}
+	}
+	if !names["tcp/NULL"] || !names["tcp/GetRequest"] {
+		t.Errorf("probes = %v, want NULL and GetRequest among them", names)
+	}
+}
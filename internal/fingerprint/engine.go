@@ -0,0 +1,222 @@
+package fingerprint
+
+import (
+	"context"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+)
+
+// maxResponse caps how much of a probe reply is buffered for matching
+const maxResponse = 16 * 1024
+
+// Engine identifies services on open ports by sending probes from a
+// database and matching the replies
+type Engine struct {
+	db        *Database
+	wait      time.Duration
+	intensity int
+}
+
+// NewEngine creates a fingerprinting engine. wait bounds how long each probe
+// waits for a reply, and intensity (1-9) limits which probes are tried on
+// ports they are not registered for.
+func NewEngine(db *Database, wait time.Duration, intensity int) *Engine {
+	return &Engine{
+		db:        db,
+		wait:      wait,
+		intensity: intensity,
+	}
+}
+
+// Enrich fingerprints the service behind an open port and records the
+// service, product, version and CPE on the result
+func (e *Engine) Enrich(ctx context.Context, r *result.Result) {
+	if r.Status != result.StatusOpen {
+		return
+	}
+
+	softService := ""
+	for _, probe := range e.probesFor(r) {
+		if ctx.Err() != nil {
+			return
+		}
+
+		// After a soft match only probes that can refine it are worth sending
+		if softService != "" && !probe.hasService(softService) {
+			continue
+		}
+
+		m, groups := e.run(ctx, probe, r)
+		if m == nil {
+			continue
+		}
+
+		if !m.Soft {
+			r.Service = m.Service
+			r.Product = expand(m.Product, groups)
+			r.Version = expand(m.Version, groups)
+			r.CPE = expand(m.CPE, groups)
+			return
+		}
+
+		if softService == "" {
+			softService = m.Service
+			r.Service = m.Service
+		}
+	}
+}
+
+// probesFor orders the probes to try against a result: the null probe
+// first, then probes registered for the port, then the rest by rarity
+func (e *Engine) probesFor(r *result.Result) []*Probe {
+	var null, registered, others []*Probe
+	for _, p := range e.db.Probes {
+		if p.Protocol != r.Protocol {
+			continue
+		}
+		switch {
+		case len(p.Payload) == 0:
+			null = append(null, p)
+		case p.Ports[r.Port]:
+			registered = append(registered, p)
+		case p.Rarity <= e.intensity:
+			others = append(others, p)
+		}
+	}
+
+	byRarity := func(probes []*Probe) {
+		sort.SliceStable(probes, func(i, j int) bool {
+			return probes[i].Rarity < probes[j].Rarity
+		})
+	}
+	byRarity(registered)
+	byRarity(others)
+
+	// UDP services never speak first, so a null probe is pointless there
+	if r.Protocol == "udp" {
+		null = nil
+	}
+
+	probes := append(null, registered...)
+	return append(probes, others...)
+}
+
+// run sends a probe on a fresh connection and matches the reply as it
+// arrives, returning the first match and its capture groups
+func (e *Engine) run(ctx context.Context, probe *Probe, r *result.Result) (*Match, []string) {
+	wait := e.wait
+	if probe.TotalWait > 0 && probe.TotalWait < wait {
+		wait = probe.TotalWait
+	}
+
+	address := net.JoinHostPort(r.IP, strconv.Itoa(r.Port))
+	dialer := net.Dialer{Timeout: wait}
+	conn, err := dialer.DialContext(ctx, r.Protocol, address)
+	if err != nil {
+		return nil, nil
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(wait)
+	conn.SetDeadline(deadline)
+
+	if len(probe.Payload) > 0 {
+		if _, err := conn.Write(probe.Payload); err != nil {
+			return nil, nil
+		}
+	}
+
+	var (
+		response []byte
+		soft     *Match
+		softArgs []string
+	)
+	buf := make([]byte, 4096)
+	for len(response) < maxResponse {
+		n, err := conn.Read(buf)
+		if n > 0 {
+			response = append(response, buf[:n]...)
+			m, groups := probe.match(response)
+			if m != nil && !m.Soft {
+				return m, groups
+			}
+			if m != nil {
+				soft, softArgs = m, groups
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	return soft, softArgs
+}
+
+// match returns the first match for a response, preferring hard matches
+func (p *Probe) match(response []byte) (*Match, []string) {
+	var soft *Match
+	var softGroups []string
+	for _, m := range p.Matches {
+		groups := m.Pattern.FindSubmatch(response)
+		if groups == nil {
+			continue
+		}
+		args := make([]string, len(groups))
+		for i, g := range groups {
+			args[i] = string(g)
+		}
+		if !m.Soft {
+			return m, args
+		}
+		if soft == nil {
+			soft, softGroups = m, args
+		}
+	}
+	return soft, softGroups
+}
+
+// hasService reports whether the probe carries any match for a service
+func (p *Probe) hasService(service string) bool {
+	for _, m := range p.Matches {
+		if m.Service == service {
+			return true
+		}
+	}
+	return false
+}
+
+// templateRef matches $1 and $P(1) references in version templates
+var templateRef = regexp.MustCompile(`\$P\((\d)\)|\$(\d)`)
+
+// expand substitutes capture groups into a version info template.
+// $P(n) keeps only the printable characters of group n.
+func expand(template string, groups []string) string {
+	if template == "" {
+		return ""
+	}
+
+	out := templateRef.ReplaceAllStringFunc(template, func(ref string) string {
+		printable := strings.HasPrefix(ref, "$P(")
+		idx, _ := strconv.Atoi(strings.Trim(ref, "$P()"))
+		if idx >= len(groups) {
+			return ""
+		}
+		if !printable {
+			return groups[idx]
+		}
+		return strings.Map(func(r rune) rune {
+			if r < 0x20 || r > 0x7e {
+				return -1
+			}
+			return r
+		}, groups[idx])
+	})
+
+	return strings.TrimSpace(out)
+}
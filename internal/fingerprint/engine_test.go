@@ -0,0 +1,261 @@
+package fingerprint
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+)
+
+func TestExpand(t *testing.T) {
+	groups := []string{"SSH-2.0-OpenSSH_9.6p1", "2.0", "9.6p1", "v\x00\x01 1"}
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"$2", "9.6p1"},
+		{"OpenSSH $2 (protocol $1)", "OpenSSH 9.6p1 (protocol 2.0)"},
+		{"$P(3)", "v 1"},
+		{"$3", "v\x00\x01 1"},
+		{"$9", ""},
+		{" $1 ", "2.0"},
+		{"plain", "plain"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := expand(tt.template, groups); got != tt.want {
+			t.Errorf("expand(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
+
+// probeNamed returns a probe of the database by name
+func probeNamed(t *testing.T, db *Database, name string) *Probe {
+	t.Helper()
+
+	for _, p := range db.Probes {
+		if p.Name == name {
+			return p
+		}
+	}
+	t.Fatalf("no probe named %s", name)
+	return nil
+}
+
+func TestDefaultMatches(t *testing.T) {
+	db, err := Default()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		probe    string
+		response string
+		service  string
+		soft     bool
+		product  string
+		version  string
+		cpe      string
+	}{
+		{"NULL", "SSH-2.0-OpenSSH_9.6p1 Ubuntu-3ubuntu13\r\n", "ssh", false, "OpenSSH", "9.6p1", "cpe:/a:openbsd:openssh:9.6p1"},
+		{"NULL", "SSH-2.0-dropbear_2022.83\r\n", "ssh", false, "Dropbear sshd", "2022.83", "cpe:/a:matt_johnston:dropbear_ssh_server:2022.83"},
+		{"NULL", "SSH-2.0-Go\r\n", "ssh", true, "", "", ""},
+		{"NULL", "220 (vsFTPd 3.0.5)\r\n", "ftp", false, "vsftpd", "3.0.5", "cpe:/a:beasts:vsftpd:3.0.5"},
+		{"NULL", "220 mail.test ESMTP Postfix (Ubuntu)\r\n", "smtp", false, "Postfix smtpd", "", "cpe:/a:postfix:postfix"},
+		{"NULL", "\x4a\x00\x00\x00\x0a8.0.36\x00\x08\x00\x00\x00", "mysql", false, "MySQL", "8.0.36", "cpe:/a:mysql:mysql:8.0.36"},
+		{"NULL", "RFB 003.008\n", "vnc", false, "VNC", "3.8", ""},
+		{"GetRequest", "HTTP/1.1 200 OK\r\nDate: today\r\nServer: nginx/1.25.3\r\n\r\n", "http", false, "nginx", "1.25.3", "cpe:/a:nginx:nginx:1.25.3"},
+		{"GetRequest", "HTTP/1.0 404 Not Found\r\nServer: Apache\r\n\r\n", "http", false, "Apache httpd", "", "cpe:/a:apache:http_server"},
+		{"GetRequest", "HTTP/1.1 200 OK\r\nServer: unknown\r\n\r\n", "http", true, "", "", ""},
+		{"redis-server", "$3000\r\n# Server\r\nredis_version:7.2.4\r\n", "redis", false, "Redis key-value store", "7.2.4", "cpe:/a:redis:redis:7.2.4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.service+" "+tt.product, func(t *testing.T) {
+			m, groups := probeNamed(t, db, tt.probe).match([]byte(tt.response))
+			if m == nil {
+				t.Fatalf("no match for %q", tt.response)
+			}
+			if m.Service != tt.service || m.Soft != tt.soft {
+				t.Errorf("service = %s (soft %v), want %s (soft %v)", m.Service, m.Soft, tt.service, tt.soft)
+			}
+			product, version, cpe := expand(m.Product, groups), expand(m.Version, groups), expand(m.CPE, groups)
+			if product != tt.product || version != tt.version || cpe != tt.cpe {
+				t.Errorf("got %q %q %q, want %q %q %q", product, version, cpe, tt.product, tt.version, tt.cpe)
+			}
+		})
+	}
+
+	if m, _ := probeNamed(t, db, "NULL").match([]byte("nothing to see\r\n")); m != nil {
+		t.Errorf("unrecognized greeting matched %s", m.Service)
+	}
+}
+
+func TestProbeMatchPrefersHard(t *testing.T) {
+	db, err := Parse(strings.NewReader("Probe TCP NULL q||\n" +
+		"softmatch ssh m|^SSH-|\n" +
+		"match ssh m|^SSH-2\\.0-Custom_([\\d.]+)| p/Custom sshd/ v/$1/\n" +
+		"softmatch ssh2 m|^SSH-2|\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	probe := db.Probes[0]
+
+	m, groups := probe.match([]byte("SSH-2.0-Custom_1.2\r\n"))
+	if m == nil || m.Soft || expand(m.Version, groups) != "1.2" {
+		t.Errorf("match = %+v, want the hard match despite the earlier softmatch", m)
+	}
+
+	m, _ = probe.match([]byte("SSH-2.0-Other\r\n"))
+	if m == nil || !m.Soft || m.Service != "ssh" {
+		t.Errorf("match = %+v, want the first softmatch", m)
+	}
+}
+
+func TestProbesFor(t *testing.T) {
+	db, err := Parse(strings.NewReader(`Probe TCP NULL q||
+match a m|^a|
+Probe TCP Rare q|rare|
+rarity 8
+match a m|^a|
+Probe TCP Common q|common|
+rarity 2
+match a m|^a|
+Probe TCP Registered q|registered|
+rarity 9
+ports 8080
+match a m|^a|
+Probe UDP Datagram q|dgram|
+ports 8080
+match a m|^a|
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		protocol  string
+		port      int
+		intensity int
+		want      []string
+	}{
+		{"registered port", "tcp", 8080, 7, []string{"NULL", "Registered", "Common"}},
+		{"other port", "tcp", 22, 7, []string{"NULL", "Common"}},
+		{"full intensity", "tcp", 22, 9, []string{"NULL", "Common", "Rare", "Registered"}},
+		{"no intensity", "tcp", 22, 0, []string{"NULL"}},
+		{"UDP skips the null probe", "udp", 8080, 9, []string{"Datagram"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEngine(db, time.Second, tt.intensity)
+			var got []string
+			for _, p := range e.probesFor(&result.Result{Protocol: tt.protocol, Port: tt.port}) {
+				got = append(got, p.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("probes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// serve runs a loopback TCP service and returns its port
+func serve(t *testing.T, handle func(net.Conn)) int {
+	t.Helper()
+
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestEnrich(t *testing.T) {
+	db, err := Parse(strings.NewReader(customDatabase))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		handle  func(net.Conn)
+		service string
+		product string
+		version string
+	}{
+		{
+			name:    "greeting",
+			handle:  func(c net.Conn) { c.Write([]byte("SSH-2.0-Custom_1.4\r\n")) },
+			service: "ssh",
+			product: "Custom sshd",
+			version: "1.4",
+		},
+		{
+			name: "answers a probe",
+			handle: func(c net.Conn) {
+				line, err := bufio.NewReader(c).ReadString('\n')
+				if err == nil && line == "HELLO\r\n" {
+					c.Write([]byte("HI v2\x01.0\r\n"))
+				}
+			},
+			service: "hello",
+			product: "Hello daemon",
+			version: "v2.0",
+		},
+		{
+			name:    "soft match only",
+			handle:  func(c net.Conn) { c.Write([]byte("SSH-1.99-Other\r\n")) },
+			service: "ssh",
+		},
+		{
+			name:   "unrecognized",
+			handle: func(c net.Conn) { c.Write([]byte("welcome\r\n")) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &result.Result{IP: "127.0.0.1", Port: serve(t, tt.handle), Protocol: "tcp", Status: result.StatusOpen}
+			NewEngine(db, 200*time.Millisecond, 7).Enrich(context.Background(), r)
+			if r.Service != tt.service || r.Product != tt.product || r.Version != tt.version {
+				t.Errorf("got %q %q %q, want %q %q %q", r.Service, r.Product, r.Version, tt.service, tt.product, tt.version)
+			}
+		})
+	}
+}
+
+func TestEnrichSkipsClosedPorts(t *testing.T) {
+	db, err := Parse(strings.NewReader(customDatabase))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &result.Result{IP: "127.0.0.1", Port: 1, Protocol: "tcp", Status: result.StatusClosed}
+	NewEngine(db, time.Second, 7).Enrich(context.Background(), r)
+	if r.Service != "" {
+		t.Errorf("service = %q for a closed port", r.Service)
+	}
+}
@@ -0,0 +1,242 @@
+package httpprobe
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+)
+
+const (
+	// maxBody caps how much of a page is read when looking for the title
+	maxBody = 1 << 20
+
+	// maxFavicon caps the size of a favicon that will be hashed
+	maxFavicon = 1 << 20
+
+	// maxRedirects caps how many same-host redirects are followed
+	maxRedirects = 10
+)
+
+// httpsPorts lists ports where HTTPS is tried before plain HTTP
+var httpsPorts = map[int]bool{
+	443: true, 4443: true, 8443: true, 9443: true, 10443: true,
+}
+
+var (
+	titlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	iconPattern  = regexp.MustCompile(`(?is)<link[^>]+rel=["']?(?:shortcut )?icon["']?[^>]*>`)
+	hrefPattern  = regexp.MustCompile(`(?is)href=["']?([^"' >]+)`)
+)
+
+// Prober issues an HTTP GET against open ports and records the response
+type Prober struct {
+	Timeout time.Duration
+}
+
+// NewProber creates a new HTTP prober
+func NewProber(timeout time.Duration) *Prober {
+	return &Prober{
+		Timeout: timeout,
+	}
+}
+
+// Enrich requests the root page of an open TCP port and attaches the
+// status, headers, title, redirect chain and favicon hash. HTTPS is tried
+// first on ports that completed a TLS handshake or conventionally use it.
+func (p *Prober) Enrich(ctx context.Context, r *result.Result) {
+	if r.Protocol != "tcp" || r.Status != result.StatusOpen {
+		return
+	}
+
+	schemes := []string{"http", "https"}
+	if r.TLS != nil || httpsPorts[r.Port] {
+		schemes = []string{"https", "http"}
+	}
+
+	for _, scheme := range schemes {
+		if ctx.Err() != nil {
+			return
+		}
+		if info := p.fetch(ctx, scheme, r); info != nil {
+			r.HTTP = info
+			return
+		}
+	}
+}
+
+// fetch performs the GET for one scheme, returning nil if the port does
+// not answer with HTTP
+func (p *Prober) fetch(ctx context.Context, scheme string, r *result.Result) *result.HTTPInfo {
+	host := net.JoinHostPort(hostFor(r), strconv.Itoa(r.Port))
+	target := &url.URL{Scheme: scheme, Host: host, Path: "/"}
+
+	info := &result.HTTPInfo{}
+	client := p.client(r, info)
+	defer client.CloseIdleConnections()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+
+	// TLS servers often answer plain HTTP with a 400 explaining the mistake
+	if scheme == "http" && resp.StatusCode == http.StatusBadRequest && wantsHTTPS(body) {
+		return nil
+	}
+
+	info.URL = resp.Request.URL.String()
+	info.StatusCode = resp.StatusCode
+	info.Server = resp.Header.Get("Server")
+	info.PoweredBy = resp.Header.Get("X-Powered-By")
+	info.ContentType = resp.Header.Get("Content-Type")
+	info.ContentLength = resp.ContentLength
+	if info.ContentLength < 0 {
+		info.ContentLength = int64(len(body))
+	}
+	info.Title = extractTitle(body)
+
+	p.hashFavicon(ctx, r, resp.Request.URL, body, info)
+
+	return info
+}
+
+// client builds an HTTP client that always connects to the scanned address
+// and refuses to follow redirects off the scanned host, recording redirect
+// targets into info when it is non-nil
+func (p *Prober) client(r *result.Result, info *result.HTTPInfo) *http.Client {
+	dialer := &net.Dialer{Timeout: p.Timeout}
+
+	transport := &http.Transport{
+		// Certificates are inspected separately; we only want the page
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		DisableKeepAlives: true,
+		// Hostnames may resolve elsewhere now; stay on the scanned address
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			_, port, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+			return dialer.DialContext(ctx, network, net.JoinHostPort(r.IP, port))
+		},
+		TLSHandshakeTimeout:   p.Timeout,
+		ResponseHeaderTimeout: p.Timeout,
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   p.Timeout * 3,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if info != nil {
+				info.Redirects = append(info.Redirects, req.URL.String())
+			}
+			// Stop at the redirect rather than following it off the host
+			if len(via) >= maxRedirects || req.URL.Hostname() != via[0].URL.Hostname() {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		},
+	}
+}
+
+// hashFavicon fetches the page's favicon and records its hashes
+func (p *Prober) hashFavicon(ctx context.Context, r *result.Result, base *url.URL, body []byte, info *result.HTTPInfo) {
+	iconURL := base.ResolveReference(&url.URL{Path: "/favicon.ico"})
+	if link := iconPattern.Find(body); link != nil {
+		if href := hrefPattern.FindSubmatch(link); href != nil {
+			if ref, err := url.Parse(html.UnescapeString(string(href[1]))); err == nil {
+				iconURL = base.ResolveReference(ref)
+			}
+		}
+	}
+
+	// Never fetch icons hosted elsewhere
+	if iconURL.Hostname() != base.Hostname() {
+		return
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, iconURL.String(), nil)
+	if err != nil {
+		return
+	}
+
+	client := p.client(r, nil)
+	defer client.CloseIdleConnections()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFavicon))
+	if err != nil || len(data) == 0 {
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	hash := faviconHash(data)
+	info.FaviconMMH3 = &hash
+	info.FaviconSHA256 = hex.EncodeToString(sum[:])
+}
+
+// httpsHints are phrases servers use when plain HTTP reaches a TLS port
+var httpsHints = []string{
+	"http request to an https server",
+	"plain http request was sent to https port",
+	"speaking plain http to an ssl-enabled server",
+	"this combination of host and port requires tls",
+}
+
+// wantsHTTPS reports whether a response body says the port expects TLS
+func wantsHTTPS(body []byte) bool {
+	lower := strings.ToLower(string(body))
+	for _, hint := range httpsHints {
+		if strings.Contains(lower, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// extractTitle returns the normalized contents of the page's <title>
+func extractTitle(body []byte) string {
+	m := titlePattern.FindSubmatch(body)
+	if m == nil {
+		return ""
+	}
+	title := html.UnescapeString(string(m[1]))
+	return strings.Join(strings.Fields(title), " ")
+}
+
+// hostFor returns the host used in request URLs for a result, preferring
+// the hostname the target was resolved from so virtual hosts match
+func hostFor(r *result.Result) string {
+	if r.Hostname != "" {
+		return r.Hostname
+	}
+	return r.IP
+}
@@ -0,0 +1,226 @@
+package httpprobe
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+)
+
+var favicon = []byte("\x00\x00\x01\x00not really an icon")
+
+// testSite serves a small site exercising titles, headers, redirects and
+// favicons
+func testSite() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Server", "test-server/1.0")
+		w.Header().Set("X-Powered-By", "Go")
+		w.Write([]byte("<html><head><title>\n  Test &amp; Site\n</title></head></html>"))
+	})
+	mux.HandleFunc("/favicon.ico", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(favicon)
+	})
+	return mux
+}
+
+func TestEnrichPage(t *testing.T) {
+	server := httptest.NewServer(testSite())
+	defer server.Close()
+
+	addr := server.Listener.Addr().(*net.TCPAddr)
+	r := &result.Result{IP: addr.IP.String(), Port: addr.Port, Protocol: "tcp", Status: result.StatusOpen}
+	NewProber(2*time.Second).Enrich(context.Background(), r)
+
+	info := r.HTTP
+	if info == nil {
+		t.Fatal("no HTTP info recorded")
+	}
+
+	sum := sha256.Sum256(favicon)
+	tests := []struct {
+		field string
+		got   any
+		want  any
+	}{
+		{"URL", info.URL, server.URL + "/"},
+		{"StatusCode", info.StatusCode, http.StatusOK},
+		{"Title", info.Title, "Test & Site"},
+		{"Server", info.Server, "test-server/1.0"},
+		{"PoweredBy", info.PoweredBy, "Go"},
+		{"FaviconSHA256", info.FaviconSHA256, hex.EncodeToString(sum[:])},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.field, tt.got, tt.want)
+		}
+	}
+	if info.FaviconMMH3 == nil || *info.FaviconMMH3 != faviconHash(favicon) {
+		t.Errorf("FaviconMMH3 = %v, want %d", info.FaviconMMH3, faviconHash(favicon))
+	}
+}
+
+func TestEnrichRedirects(t *testing.T) {
+	tests := []struct {
+		name      string
+		location  string
+		status    int
+		redirects int
+	}{
+		{"same host is followed", "/login", http.StatusOK, 1},
+		{"other host is not", "http://example.com/", http.StatusFound, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, tt.location, http.StatusFound)
+			})
+			mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {})
+
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			addr := server.Listener.Addr().(*net.TCPAddr)
+			r := &result.Result{IP: addr.IP.String(), Port: addr.Port, Protocol: "tcp", Status: result.StatusOpen}
+			NewProber(2*time.Second).Enrich(context.Background(), r)
+
+			if r.HTTP == nil {
+				t.Fatal("no HTTP info recorded")
+			}
+			if r.HTTP.StatusCode != tt.status {
+				t.Errorf("StatusCode = %d, want %d", r.HTTP.StatusCode, tt.status)
+			}
+			if len(r.HTTP.Redirects) != tt.redirects {
+				t.Errorf("Redirects = %v, want %d", r.HTTP.Redirects, tt.redirects)
+			}
+		})
+	}
+}
+
+func TestEnrichSchemes(t *testing.T) {
+	plain := httptest.NewServer(testSite())
+	defer plain.Close()
+
+	// Plain HTTP is tried first on unconventional ports, so the TLS server
+	// sees, and would log, one bad handshake
+	secure := httptest.NewUnstartedServer(testSite())
+	secure.Config.ErrorLog = log.New(io.Discard, "", 0)
+	secure.StartTLS()
+	defer secure.Close()
+
+	// A listener that accepts and hangs up without speaking HTTP
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("SSH-2.0-test\r\n"))
+			conn.Close()
+		}
+	}()
+
+	tests := []struct {
+		name   string
+		addr   net.Addr
+		scheme string
+	}{
+		{"plain HTTP", plain.Listener.Addr(), "http"},
+		{"HTTPS", secure.Listener.Addr(), "https"},
+		{"not HTTP", ln.Addr(), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := tt.addr.(*net.TCPAddr)
+			r := &result.Result{IP: addr.IP.String(), Port: addr.Port, Protocol: "tcp", Status: result.StatusOpen}
+			NewProber(2*time.Second).Enrich(context.Background(), r)
+
+			if tt.scheme == "" {
+				if r.HTTP != nil {
+					t.Fatalf("HTTP = %+v, want none", r.HTTP)
+				}
+				return
+			}
+			if r.HTTP == nil {
+				t.Fatal("no HTTP info recorded")
+			}
+			if want := tt.scheme + "://" + addr.String() + "/"; r.HTTP.URL != want {
+				t.Errorf("URL = %s, want %s", r.HTTP.URL, want)
+			}
+		})
+	}
+}
+
+func TestExtractTitle(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{"<title>Home</title>", "Home"},
+		{`<TITLE lang="en">A  &lt;b&gt;</TITLE>`, "A <b>"},
+		{"<title>\n\tline\n\tbreaks\n</title>", "line breaks"},
+		{"<h1>No title</h1>", ""},
+	}
+
+	for _, tt := range tests {
+		if got := extractTitle([]byte(tt.body)); got != tt.want {
+			t.Errorf("extractTitle(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestMMH3(t *testing.T) {
+	tests := []struct {
+		data string
+		want uint32
+	}{
+		{"", 0},
+		{"hello", 0x248bfa47},
+		{"Hello, world!", 0xc0363e43},
+		{"The quick brown fox jumps over the lazy dog", 0x2e4ff723},
+	}
+
+	for _, tt := range tests {
+		if got := mmh3([]byte(tt.data)); got != tt.want {
+			t.Errorf("mmh3(%q) = %#x, want %#x", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestWantsHTTPS(t *testing.T) {
+	tests := []struct {
+		body string
+		want bool
+	}{
+		{"Client sent an HTTP request to an HTTPS server.", true},
+		{"<h1>400 The plain HTTP request was sent to HTTPS port</h1>", true},
+		{"Bad Request", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := wantsHTTPS([]byte(tt.body)); got != tt.want {
+			t.Errorf("wantsHTTPS(%q) = %v, want %v", tt.body, got, tt.want)
+		}
+	}
+}
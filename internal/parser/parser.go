@@ -1,123 +1,257 @@
 package parser
 
 import (
+	"context"
 	"fmt"
 	"net"
+	"net/netip"
 	"strconv"
 	"strings"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/scope"
 )
 
-// ParseTargets converts a list of IP addresses and CIDR ranges into individual IPs
-func ParseTargets(targets []string) ([]string, error) {
-	var ips []string
+// Target is a single address to scan, along with the hostname it was
+// resolved from (empty for IP and CIDR targets)
+type Target struct {
+	IP       string
+	Hostname string
+}
+
+// TargetOptions controls how target specifications are expanded
+type TargetOptions struct {
+	// Resolver resolves hostname targets (nil = system resolver)
+	Resolver *Resolver
+
+	// SampleSize limits prefixes holding more addresses than this to a
+	// pseudo-random sample of SampleSize addresses (0 = walk every address)
+	SampleSize uint64
+
+	// Seed selects the sample drawn from large prefixes
+	Seed uint64
+
+	// Scope refuses targets the scan is not authorized to touch (nil =
+	// no restrictions)
+	Scope *scope.Scope
+}
+
+// ParseTargets converts a list of IP addresses, CIDR ranges, address ranges
+// and hostnames into a TargetSet. Ranges are not expanded up front: each
+// specification becomes a block whose addresses are computed as the set is
+// iterated.
+//
+// Each single address, whether given directly or resolved from a hostname,
+// is scanned once even if another target repeats or contains it. Ranges
+// that overlap each other are still walked in full, as finding their
+// overlap would mean expanding them.
+//
+// Hostname lookups are abandoned if ctx ends first.
+func ParseTargets(ctx context.Context, targets []string, opts TargetOptions) (*TargetSet, error) {
+	var blocks []Block
 	seen := make(map[string]bool)
 
+	specs := make([]string, 0, len(targets))
 	for _, target := range targets {
-		target = strings.TrimSpace(target)
-		if target == "" {
+		if target = strings.TrimSpace(target); target != "" {
+			specs = append(specs, target)
+		}
+	}
+
+	for _, target := range joinOctetLists(specs) {
+		if seen[target] {
 			continue
 		}
+		seen[target] = true
 
-		// Check if it's a CIDR range
-		if strings.Contains(target, "/") {
-			rangeIPs, err := parseCIDR(target)
-			if err != nil {
-				return nil, fmt.Errorf("invalid CIDR %s: %w", target, err)
-			}
-			for _, ip := range rangeIPs {
-				if !seen[ip] {
-					ips = append(ips, ip)
-					seen[ip] = true
-				}
-			}
-		} else {
-			// Single IP address
-			if net.ParseIP(target) == nil {
-				return nil, fmt.Errorf("invalid IP address: %s", target)
-			}
-			if !seen[target] {
-				ips = append(ips, target)
-				seen[target] = true
-			}
+		block, err := parseTarget(ctx, target, opts)
+		if err != nil {
+			return nil, err
 		}
+		blocks = append(blocks, block)
 	}
 
-	if len(ips) == 0 {
+	set := &TargetSet{}
+	addrs := make(map[netip.Addr]bool)
+	for _, block := range blocks {
+		if list, ok := block.(listBlock); ok {
+			block = uniqueTargets(list, blocks, addrs)
+		}
+		set.add(block)
+	}
+
+	if set.Len() == 0 {
 		return nil, fmt.Errorf("no valid targets found")
 	}
 
-	return ips, nil
+	return set, nil
 }
 
-// parseCIDR expands a CIDR range into individual IP addresses
-func parseCIDR(cidr string) ([]string, error) {
-	ip, ipNet, err := net.ParseCIDR(cidr)
-	if err != nil {
-		return nil, err
+// uniqueTargets drops the targets of a list whose address was already
+// listed, as recorded in seen, or lies inside one of the range blocks
+func uniqueTargets(list listBlock, blocks []Block, seen map[netip.Addr]bool) listBlock {
+	kept := make(listBlock, 0, len(list))
+	for _, t := range list {
+		addr, err := netip.ParseAddr(t.IP)
+		if err != nil {
+			kept = append(kept, t)
+			continue
+		}
+		addr = addr.WithZone("")
+		if seen[addr] || rangesContain(blocks, addr) {
+			continue
+		}
+		seen[addr] = true
+		kept = append(kept, t)
 	}
+	return kept
+}
 
-	var ips []string
-	for ip := ip.Mask(ipNet.Mask); ipNet.Contains(ip); incrementIP(ip) {
-		ips = append(ips, ip.String())
+// rangesContain reports whether any of the blocks walking a range holds
+// addr. Samples are left out, as they may not include it.
+func rangesContain(blocks []Block, addr netip.Addr) bool {
+	for _, b := range blocks {
+		if r, ok := b.(interface{ contains(netip.Addr) bool }); ok && r.contains(addr) {
+			return true
+		}
 	}
+	return false
+}
 
-	// Remove network and broadcast addresses for typical /24 and smaller
-	if len(ips) > 2 {
-		ips = ips[1 : len(ips)-1]
+// parseTarget converts a single target specification into a block
+func parseTarget(ctx context.Context, target string, opts TargetOptions) (Block, error) {
+	// Check if it's a CIDR range
+	if strings.Contains(target, "/") {
+		prefix, err := netip.ParsePrefix(target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %s: %w", target, err)
+		}
+		if opts.Scope != nil {
+			if err := opts.Scope.CheckPrefix(prefix); err != nil {
+				return nil, err
+			}
+		}
+		return newPrefixBlock(prefix, opts.SampleSize, opts.Seed), nil
 	}
 
-	return ips, nil
-}
+	// Single IP address
+	if addr, err := netip.ParseAddr(target); err == nil {
+		if opts.Scope != nil {
+			if err := opts.Scope.CheckAddr(addr, ""); err != nil {
+				return nil, err
+			}
+		}
+		return listBlock{{IP: addr.Unmap().String()}}, nil
+	}
+
+	// Address ranges such as 10.0.0.5-10.0.1.20 or 10.0.1-3.1-254
+	if block, ok, err := parseRange(target, opts); ok {
+		return block, err
+	}
+
+	if !isHostname(target) {
+		return nil, fmt.Errorf("invalid target: %s (expected IP, CIDR, range or hostname)", target)
+	}
+
+	// Hostname: scan every address it resolves to
+	resolver := opts.Resolver
+	if resolver == nil {
+		resolver = &Resolver{resolver: net.DefaultResolver, network: "ip"}
+	}
+
+	addrs, err := resolver.Lookup(ctx, target)
+	if err != nil {
+		return nil, err
+	}
 
-// incrementIP increments an IP address by one
-func incrementIP(ip net.IP) {
-	for j := len(ip) - 1; j >= 0; j-- {
-		ip[j]++
-		if ip[j] > 0 {
-			break
+	// Addresses outside the scope are dropped; the hostname is refused
+	// only if none remain
+	var scopeErr error
+	block := make(listBlock, 0, len(addrs))
+	for _, ip := range addrs {
+		if opts.Scope != nil {
+			if err := opts.Scope.CheckAddr(netip.MustParseAddr(ip), target); err != nil {
+				scopeErr = err
+				continue
+			}
 		}
+		block = append(block, Target{IP: ip, Hostname: target})
 	}
+	if len(block) == 0 && scopeErr != nil {
+		return nil, scopeErr
+	}
+	return block, nil
 }
 
-// ParsePorts converts a port specification string into individual ports
-// Supports formats: "80", "80,443", "80-100", "80,443,8000-9000"
-func ParsePorts(portSpec string) ([]int, error) {
+// ParsePorts converts a port specification into the ports to scan for a
+// protocol ("tcp" or "udp"). The specification is a comma-separated list
+// of items, each of which may be:
+//
+//   - a port (80) or range (8000-9000); ranges may be open-ended (60000-,
+//     -1024), and "-" alone means every port
+//   - a service name from the embedded services table (ssh, http)
+//   - topN: the N ports of the protocol most often found open (top100)
+//   - any of the above preceded by "!" to exclude its ports
+//
+// "T:" or "U:" before an item restricts it and the items that follow to
+// TCP or UDP, as in "T:80,443,U:53".
+func ParsePorts(portSpec, protocol string) ([]int, error) {
 	var ports []int
 	seen := make(map[int]bool)
+	excluded := make(map[int]bool)
+	itemProtocol := protocol
 
 	parts := strings.Split(portSpec, ",")
 	for _, part := range parts {
 		part = strings.TrimSpace(part)
+		if prefix, rest, ok := strings.Cut(part, ":"); ok {
+			switch strings.ToUpper(prefix) {
+			case "T":
+				itemProtocol = "tcp"
+			case "U":
+				itemProtocol = "udp"
+			default:
+				return nil, fmt.Errorf("invalid protocol prefix in %s (expected T: or U:)", part)
+			}
+			part = strings.TrimSpace(rest)
+		}
 		if part == "" {
 			continue
 		}
 
-		// Check if it's a range
-		if strings.Contains(part, "-") {
-			rangePorts, err := parsePortRange(part)
-			if err != nil {
-				return nil, err
-			}
-			for _, p := range rangePorts {
-				if !seen[p] {
-					ports = append(ports, p)
-					seen[p] = true
-				}
-			}
-		} else {
-			// Single port
-			port, err := strconv.Atoi(part)
-			if err != nil {
-				return nil, fmt.Errorf("invalid port: %s", part)
-			}
-			if err := validatePort(port); err != nil {
-				return nil, err
+		// Items for the other protocol are neither expanded nor checked
+		if itemProtocol != protocol {
+			continue
+		}
+
+		exclude := false
+		if rest, ok := strings.CutPrefix(part, "!"); ok {
+			exclude = true
+			part = strings.TrimSpace(rest)
+		}
+
+		itemPorts, err := parsePortItem(part, itemProtocol)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range itemPorts {
+			if exclude {
+				excluded[p] = true
+			} else if !seen[p] {
+				ports = append(ports, p)
+				seen[p] = true
 			}
-			if !seen[port] {
-				ports = append(ports, port)
-				seen[port] = true
+		}
+	}
+
+	if len(excluded) > 0 {
+		kept := ports[:0]
+		for _, p := range ports {
+			if !excluded[p] {
+				kept = append(kept, p)
 			}
 		}
+		ports = kept
 	}
 
 	if len(ports) == 0 {
@@ -127,21 +261,62 @@ func ParsePorts(portSpec string) ([]int, error) {
 	return ports, nil
 }
 
-// parsePortRange parses a port range like "8000-9000"
-func parsePortRange(rangeSpec string) ([]int, error) {
-	parts := strings.Split(rangeSpec, "-")
-	if len(parts) != 2 {
-		return nil, fmt.Errorf("invalid port range: %s", rangeSpec)
+// parsePortItem expands a single port, range, service name or topN set
+func parsePortItem(item, protocol string) ([]int, error) {
+	if isPortRange(item) {
+		return parsePortRange(item)
 	}
 
-	start, err := strconv.Atoi(strings.TrimSpace(parts[0]))
-	if err != nil {
-		return nil, fmt.Errorf("invalid start port in range %s: %w", rangeSpec, err)
+	if isDigits(item) {
+		port, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port: %s", item)
+		}
+		if err := validatePort(port); err != nil {
+			return nil, err
+		}
+		return []int{port}, nil
 	}
 
-	end, err := strconv.Atoi(strings.TrimSpace(parts[1]))
-	if err != nil {
-		return nil, fmt.Errorf("invalid end port in range %s: %w", rangeSpec, err)
+	if n, ok := strings.CutPrefix(strings.ToLower(item), "top"); ok && isDigits(n) {
+		count, err := strconv.Atoi(n)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port set: %s", item)
+		}
+		return topPorts(count, protocol)
+	}
+
+	return servicePorts(item, protocol)
+}
+
+// isPortRange reports whether item is a range of port numbers, either end
+// of which may be omitted
+func isPortRange(item string) bool {
+	start, end, ok := strings.Cut(item, "-")
+	return ok && (start == "" || isDigits(start)) && (end == "" || isDigits(end))
+}
+
+// isDigits reports whether s is a non-empty string of decimal digits
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+// parsePortRange parses a port range like "8000-9000". A missing start
+// means port 1 and a missing end port 65535.
+func parsePortRange(rangeSpec string) ([]int, error) {
+	startStr, endStr, _ := strings.Cut(rangeSpec, "-")
+
+	start, end := 1, 65535
+	var err error
+	if startStr != "" {
+		if start, err = strconv.Atoi(startStr); err != nil {
+			return nil, fmt.Errorf("invalid start port in range %s: %w", rangeSpec, err)
+		}
+	}
+	if endStr != "" {
+		if end, err = strconv.Atoi(endStr); err != nil {
+			return nil, fmt.Errorf("invalid end port in range %s: %w", rangeSpec, err)
+		}
 	}
 
 	if err := validatePort(start); err != nil {
@@ -155,11 +330,7 @@ func parsePortRange(rangeSpec string) ([]int, error) {
 		return nil, fmt.Errorf("start port %d is greater than end port %d", start, end)
 	}
 
-	if end-start > 65535 {
-		return nil, fmt.Errorf("port range too large: %d ports", end-start+1)
-	}
-
-	var ports []int
+	ports := make([]int, 0, end-start+1)
 	for p := start; p <= end; p++ {
 		ports = append(ports, p)
 	}
@@ -173,4 +344,4 @@ func validatePort(port int) error {
 		return fmt.Errorf("port %d out of valid range (1-65535)", port)
 	}
 	return nil
-}
\ No newline at end of file
+}
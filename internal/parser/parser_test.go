@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParsePorts(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		protocol string
+		want     []int
+		wantErr  bool
+	}{
+		{"single", "80", "tcp", []int{80}, false},
+		{"list keeps order", "443,22,80", "tcp", []int{443, 22, 80}, false},
+		{"duplicates", "80,80,79-81", "tcp", []int{80, 79, 81}, false},
+		{"range", "8000-8003", "tcp", []int{8000, 8001, 8002, 8003}, false},
+		{"open-ended range", "65533-", "tcp", []int{65533, 65534, 65535}, false},
+		{"range from 1", "-3", "tcp", []int{1, 2, 3}, false},
+		{"service name", "ssh", "tcp", []int{22}, false},
+		{"alias", "www", "tcp", []int{80}, false},
+		{"service with several ports", "http", "tcp", []int{80, 8008}, false},
+		{"service per protocol", "snmp", "udp", []int{161}, false},
+		{"top ports", "top5", "tcp", []int{80, 23, 443, 21, 22}, false},
+		{"exclusion", "1-5,!3", "tcp", []int{1, 2, 4, 5}, false},
+		{"exclusion first", "!ssh,20-23", "tcp", []int{20, 21, 23}, false},
+		{"spaces", " 22 , 80 ", "tcp", []int{22, 80}, false},
+		{"protocol prefixes", "T:80,443,U:53", "tcp", []int{80, 443}, false},
+		{"protocol prefixes for UDP", "T:80,443,U:53", "udp", []int{53}, false},
+		{"other protocol not expanded", "T:22,U:top1000", "tcp", []int{22}, false},
+		{"other protocol not validated", "T:22,U:nonsense", "tcp", []int{22}, false},
+		{"zero", "0", "tcp", nil, true},
+		{"too large", "65536", "tcp", nil, true},
+		{"reversed range", "90-80", "tcp", nil, true},
+		{"unknown service", "nonsense", "tcp", nil, true},
+		{"service without the protocol", "ntp", "tcp", nil, true},
+		{"too many top ports", "top100000", "tcp", nil, true},
+		{"top zero", "top0", "tcp", nil, true},
+		{"bad prefix", "X:80", "tcp", nil, true},
+		{"everything excluded", "22,!22", "tcp", nil, true},
+		{"only the other protocol", "U:53", "tcp", nil, true},
+		{"empty", "", "tcp", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePorts(tt.spec, tt.protocol)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePorts(%q, %s) error = %v, want error = %v", tt.spec, tt.protocol, err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ParsePorts(%q, %s) = %v, want %v", tt.spec, tt.protocol, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePortsEveryPort(t *testing.T) {
+	ports, err := ParsePorts("-", "tcp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ports) != 65535 || ports[0] != 1 || ports[len(ports)-1] != 65535 {
+		t.Errorf("ParsePorts(\"-\") = %d ports from %d to %d, want 1-65535", len(ports), ports[0], ports[len(ports)-1])
+	}
+}
+
+func TestTopPorts(t *testing.T) {
+	tests := []struct {
+		spec     string
+		protocol string
+		count    int
+	}{
+		{"top1000", "tcp", 1000},
+		{"top100", "udp", 100},
+	}
+
+	for _, tt := range tests {
+		ports, err := ParsePorts(tt.spec, tt.protocol)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Ports are deduplicated, so a repeated entry in the table would
+		// leave the set short
+		if len(ports) != tt.count {
+			t.Errorf("%s %s has %d ports, want %d", tt.spec, tt.protocol, len(ports), tt.count)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package parser
+
+import "math/bits"
+
+// feistelRounds is the number of rounds in the permutation network. Four
+// rounds are enough to scatter neighbouring indices across the range.
+const feistelRounds = 4
+
+// Permutation is a seeded bijection over [0, size) built from a balanced
+// Feistel network with cycle-walking. It maps any index to its shuffled
+// position in O(1) time and memory, so a large index space, such as every
+// host×port pair of a scan, can be visited in random order without
+// materializing it.
+type Permutation struct {
+	size     uint64
+	halfBits uint
+	mask     uint64
+	keys     [feistelRounds]uint64
+}
+
+// NewPermutation creates a permutation of [0, size) selected by seed
+func NewPermutation(size, seed uint64) *Permutation {
+	// The network permutes 2*halfBits bits; pick the smallest even width
+	// covering size so cycle-walking needs few steps on average
+	width := uint(bits.Len64(size - 1))
+	if size <= 1 {
+		width = 0
+	}
+	if width%2 == 1 {
+		width++
+	}
+	halfBits := width / 2
+
+	p := &Permutation{
+		size:     size,
+		halfBits: halfBits,
+		mask:     uint64(1)<<halfBits - 1,
+	}
+
+	key := seed
+	for i := range p.keys {
+		key = splitmix64(key + uint64(i) + 1)
+		p.keys[i] = key
+	}
+
+	return p
+}
+
+// At returns the shuffled position of index i, for 0 <= i < size
+func (p *Permutation) At(i uint64) uint64 {
+	if p.size <= 1 {
+		return i
+	}
+
+	// Cycle-walk: re-encrypt until the value falls inside the domain.
+	// Since the network is a bijection on [0, 2^width) this terminates and
+	// remains a bijection on [0, size).
+	x := p.encrypt(i)
+	for x >= p.size {
+		x = p.encrypt(x)
+	}
+	return x
+}
+
+// encrypt applies the Feistel network to a value of 2*halfBits bits
+func (p *Permutation) encrypt(x uint64) uint64 {
+	left := (x >> p.halfBits) & p.mask
+	right := x & p.mask
+
+	for _, key := range p.keys {
+		left, right = right, left^(splitmix64(right^key)&p.mask)
+	}
+
+	return left<<p.halfBits | right
+}
@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPermutationBijective(t *testing.T) {
+	sizes := []uint64{1, 2, 3, 4, 7, 16, 17, 255, 1000, 1 << 16, 1<<16 + 1}
+
+	for _, size := range sizes {
+		for _, seed := range []uint64{0, 1, 0xdeadbeef} {
+			p := NewPermutation(size, seed)
+			seen := make([]bool, size)
+			for i := range size {
+				x := p.At(i)
+				if x >= size {
+					t.Fatalf("size %d seed %d: At(%d) = %d, out of range", size, seed, i, x)
+				}
+				if seen[x] {
+					t.Fatalf("size %d seed %d: At(%d) = %d, repeated", size, seed, i, x)
+				}
+				seen[x] = true
+			}
+		}
+	}
+}
+
+func TestPermutationSeed(t *testing.T) {
+	const size = 1000
+
+	tests := []struct {
+		name     string
+		a, b     uint64
+		wantSame bool
+	}{
+		{"same seed", 7, 7, true},
+		{"different seed", 7, 8, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pa, pb := NewPermutation(size, tt.a), NewPermutation(size, tt.b)
+			same := true
+			for i := range uint64(size) {
+				if pa.At(i) != pb.At(i) {
+					same = false
+					break
+				}
+			}
+			if same != tt.wantSame {
+				t.Errorf("orders identical = %v, want %v", same, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestPermutationShuffles(t *testing.T) {
+	// Neighbouring indices should not stay neighbours
+	p := NewPermutation(1<<20, 1)
+	adjacent := 0
+	for i := range uint64(1000) {
+		if d := int64(p.At(i+1)) - int64(p.At(i)); d == 1 || d == -1 {
+			adjacent++
+		}
+	}
+	if adjacent > 10 {
+		t.Errorf("%d of 1000 neighbouring indices stayed adjacent", adjacent)
+	}
+}
+
+func TestPermutationLarge(t *testing.T) {
+	// Index spaces too large to walk must still map into range
+	for _, size := range []uint64{1<<40 + 3, math.MaxUint64} {
+		p := NewPermutation(size, 99)
+		for _, i := range []uint64{0, 1, size / 2, size - 1} {
+			if x := p.At(i); x >= size {
+				t.Errorf("size %d: At(%d) = %d, out of range", size, i, x)
+			}
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/scope"
+)
+
+// maxScopeRuns bounds the contiguous runs of a range target checked against
+// the scope one by one; larger patterns are checked as a single range from
+// their lowest address to their highest
+const maxScopeRuns = 1 << 16
+
+// parseRange parses nmap-style range targets: a start-end address range
+// such as 10.0.0.5-10.0.1.20, or an address whose octets hold ranges and
+// lists such as 10.0.1-3.1-254 or 192.168.1.10-50. IPv6 addresses take the
+// same forms with groups in place of octets, e.g. 2001:db8::1-ff. It
+// reports false when target uses neither syntax.
+func parseRange(target string, opts TargetOptions) (Block, bool, error) {
+	var (
+		block  Block
+		ranges []scope.Range
+		err    error
+	)
+
+	start, end, _ := strings.Cut(target, "-")
+	first, firstErr := netip.ParseAddr(start)
+	last, lastErr := netip.ParseAddr(end)
+
+	switch {
+	case firstErr == nil && lastErr == nil:
+		first, last = first.Unmap(), last.Unmap()
+		if block, err = newAddrRangeBlock(target, first, last); err == nil {
+			ranges = []scope.Range{{First: first, Last: last}}
+		}
+	case strings.Contains(target, ":"):
+		var p patternBlock
+		if p, err = parseGroups(target); err == nil {
+			block, ranges = p, p.ranges()
+		}
+	case strings.Contains(target, ".") && isOctetPattern(target[strings.LastIndex(target, ".")+1:]):
+		// Hostnames never end in a numeric label, so this is an IPv4
+		// pattern, possibly with a malformed octet
+		var p patternBlock
+		if p, err = parseOctets(target); err == nil {
+			block, ranges = p, p.ranges()
+		}
+	default:
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, true, err
+	}
+
+	if opts.Scope != nil {
+		if err := opts.Scope.CheckRanges(target, ranges); err != nil {
+			return nil, true, err
+		}
+	}
+
+	return block, true, nil
+}
+
+// newAddrRangeBlock builds the block for a start-end address range
+func newAddrRangeBlock(target string, first, last netip.Addr) (Block, error) {
+	if first.Is4() != last.Is4() {
+		return nil, fmt.Errorf("invalid range %s: start and end are different address families", target)
+	}
+	if last.Less(first) {
+		return nil, fmt.Errorf("invalid range %s: %s comes after %s", target, first, last)
+	}
+
+	return rangeBlock{first: first, count: satAdd(addrDiff(first, last), 1)}, nil
+}
+
+// parseOctets parses an IPv4 address whose octets may hold ranges ("1-3",
+// "10-", "-50"), lists ("1,5,7") or "*" for every value
+func parseOctets(target string) (patternBlock, error) {
+	parts := strings.Split(target, ".")
+	if len(parts) != 4 {
+		return patternBlock{}, fmt.Errorf("invalid target %s: expected 4 octets, got %d", target, len(parts))
+	}
+
+	groups := make([][]span, len(parts))
+	for i, part := range parts {
+		spans, err := parseSpans(part, 10, math.MaxUint8)
+		if err != nil {
+			return patternBlock{}, fmt.Errorf("invalid target %s: octet %d (%s): %w", target, i+1, part, err)
+		}
+		groups[i] = spans
+	}
+
+	return newPatternBlock(groups, 8), nil
+}
+
+// parseGroups parses an IPv6 address whose groups may hold ranges as in
+// parseOctets, e.g. 2001:db8::1-ff or 2001:db8:0-3::1
+func parseGroups(target string) (patternBlock, error) {
+	split := func(s string) []string {
+		if s == "" {
+			return nil
+		}
+		return strings.Split(s, ":")
+	}
+
+	head, tail, compressed := strings.Cut(target, "::")
+	parts := split(head)
+	if compressed {
+		if strings.Contains(tail, "::") {
+			return patternBlock{}, fmt.Errorf("invalid target %s: \"::\" may appear only once", target)
+		}
+		tailParts := split(tail)
+		missing := 8 - len(parts) - len(tailParts)
+		if missing < 1 {
+			return patternBlock{}, fmt.Errorf("invalid target %s: too many groups for \"::\"", target)
+		}
+		for ; missing > 0; missing-- {
+			parts = append(parts, "0")
+		}
+		parts = append(parts, tailParts...)
+	} else if len(parts) != 8 {
+		return patternBlock{}, fmt.Errorf("invalid target %s: expected 8 groups, got %d", target, len(parts))
+	}
+
+	groups := make([][]span, len(parts))
+	for i, part := range parts {
+		spans, err := parseSpans(part, 16, math.MaxUint16)
+		if err != nil {
+			return patternBlock{}, fmt.Errorf("invalid target %s: group %d (%s): %w", target, i+1, part, err)
+		}
+		groups[i] = spans
+	}
+
+	return newPatternBlock(groups, 16), nil
+}
+
+// parseSpans parses one octet or group: a comma-separated list of values
+// and ranges, sorted and merged
+func parseSpans(token string, base int, limit uint32) ([]span, error) {
+	if token == "" {
+		return nil, errors.New("empty value")
+	}
+
+	value := func(s string, fallback uint32) (uint32, error) {
+		if s == "" {
+			return fallback, nil
+		}
+		n, err := strconv.ParseUint(s, base, 32)
+		if err != nil {
+			if base == 16 {
+				return 0, fmt.Errorf("%q is not a hexadecimal number", s)
+			}
+			return 0, fmt.Errorf("%q is not a number", s)
+		}
+		if n > uint64(limit) {
+			return 0, fmt.Errorf("%s exceeds %s", s, strconv.FormatUint(uint64(limit), base))
+		}
+		return uint32(n), nil
+	}
+
+	var spans []span
+	for _, item := range strings.Split(token, ",") {
+		if item == "*" {
+			spans = append(spans, span{lo: 0, hi: limit})
+			continue
+		}
+
+		loStr, hiStr, isRange := strings.Cut(item, "-")
+		if !isRange {
+			hiStr = loStr
+		}
+		if item == "" || (!isRange && loStr == "") {
+			return nil, errors.New("empty list item")
+		}
+
+		lo, err := value(loStr, 0)
+		if err != nil {
+			return nil, err
+		}
+		hi, err := value(hiStr, limit)
+		if err != nil {
+			return nil, err
+		}
+		if hi < lo {
+			return nil, fmt.Errorf("range %s ends before it starts", item)
+		}
+		spans = append(spans, span{lo: lo, hi: hi})
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].lo < spans[j].lo })
+	merged := spans[:1]
+	for _, s := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if s.lo <= last.hi+1 {
+			last.hi = max(last.hi, s.hi)
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged, nil
+}
+
+// ranges returns the pattern's addresses as contiguous runs for checking
+// against the scope, or its overall bounds when it has too many runs
+func (b patternBlock) ranges() []scope.Range {
+	n := len(b.groups)
+	lastSpans := b.groups[n-1]
+
+	outer := uint64(1)
+	for _, size := range b.sizes[:n-1] {
+		outer = SatMul(outer, size)
+	}
+
+	values := make([]uint32, n)
+	if SatMul(outer, uint64(len(lastSpans))) > maxScopeRuns {
+		for g, spans := range b.groups {
+			values[g] = spans[0].lo
+		}
+		first := b.addr(values)
+		for g, spans := range b.groups {
+			values[g] = spans[len(spans)-1].hi
+		}
+		return []scope.Range{{First: first, Last: b.addr(values)}}
+	}
+
+	var ranges []scope.Range
+	for i := uint64(0); i < outer; i++ {
+		rest := i
+		for g := n - 2; g >= 0; g-- {
+			values[g] = nthValue(b.groups[g], rest%b.sizes[g])
+			rest /= b.sizes[g]
+		}
+		for _, s := range lastSpans {
+			values[n-1] = s.lo
+			first := b.addr(values)
+			values[n-1] = s.hi
+			ranges = append(ranges, scope.Range{First: first, Last: b.addr(values)})
+		}
+	}
+	return ranges
+}
+
+// isOctetPattern reports whether s holds only the characters of an IPv4
+// octet pattern
+func isOctetPattern(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789.,-*") == ""
+}
+
+// joinOctetLists rejoins octet lists that were split apart on commas: in
+// "10.0.1,3.1-254" or "192.168.1.1,5" the piece after each comma continues
+// the pattern before it rather than starting a new target
+func joinOctetLists(specs []string) []string {
+	joined := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		if n := len(joined); n > 0 && continuesPattern(joined[n-1], spec) {
+			joined[n-1] += "," + spec
+			continue
+		}
+		joined = append(joined, spec)
+	}
+	return joined
+}
+
+// continuesPattern reports whether next belongs to the IPv4 octet pattern
+// prev: either prev is missing octets, or next lists further values for
+// its last octet
+func continuesPattern(prev, next string) bool {
+	if !isOctetPattern(prev) || !isOctetPattern(next) {
+		return false
+	}
+	return strings.Count(prev, ".") < 3 || !strings.Contains(next, ".")
+}
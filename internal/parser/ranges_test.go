@@ -0,0 +1,104 @@
+package parser
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		spec        string
+		count       uint64
+		first, last string
+	}{
+		{"10.0.0.5-10.0.1.20", 272, "10.0.0.5", "10.0.1.20"},
+		{"10.0.0.5-10.0.0.5", 1, "10.0.0.5", "10.0.0.5"},
+		{"192.168.1.10-50", 41, "192.168.1.10", "192.168.1.50"},
+		{"10.0.1-3.1-254", 762, "10.0.1.1", "10.0.3.254"},
+		{"10.0.0.250-", 6, "10.0.0.250", "10.0.0.255"},
+		{"10.0.0.-3", 4, "10.0.0.0", "10.0.0.3"},
+		{"10.0.0.*", 256, "10.0.0.0", "10.0.0.255"},
+		{"10.0.0.1,5,7-8", 4, "10.0.0.1", "10.0.0.8"},
+		{"10.0.0.5,1,3-4,4", 4, "10.0.0.1", "10.0.0.5"},
+		{"2001:db8::1-ff", 255, "2001:db8::1", "2001:db8::ff"},
+		{"2001:db8:0-3::1", 4, "2001:db8::1", "2001:db8:3::1"},
+		{"2001:db8::1-2001:db8::10", 16, "2001:db8::1", "2001:db8::10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			set, err := ParseTargets(context.Background(), []string{tt.spec}, TargetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if set.Len() != tt.count {
+				t.Fatalf("Len() = %d, want %d", set.Len(), tt.count)
+			}
+			if got := set.At(0).IP; got != tt.first {
+				t.Errorf("first = %s, want %s", got, tt.first)
+			}
+			if got := set.At(set.Len() - 1).IP; got != tt.last {
+				t.Errorf("last = %s, want %s", got, tt.last)
+			}
+		})
+	}
+}
+
+func TestParseRangeOrder(t *testing.T) {
+	set, err := ParseTargets(context.Background(), []string{"10.0.1-2.3,1"}, TargetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The last octet varies fastest and lists come out sorted
+	want := []string{"10.0.1.1", "10.0.1.3", "10.0.2.1", "10.0.2.3"}
+	var got []string
+	for _, target := range collect(set) {
+		got = append(got, target.IP)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("targets = %v, want %v", got, want)
+	}
+}
+
+func TestParseRangeErrors(t *testing.T) {
+	tests := []string{
+		"10.0.0.20-10.0.0.5",   // end before start
+		"10.0.0.1-2001:db8::1", // mixed families
+		"10.0.0.300",           // octet out of range
+		"10.0.0.1-300",         // range end out of range
+		"10.0.0.9-3",           // reversed octet range
+		"10.0.1-3",             // missing octet
+		"10.0.0.1.1",           // extra octet
+		"10.0..1",              // empty octet
+		"2001:db8::1::2",       // two "::"
+		"2001:db8::1-10000",    // group out of range
+		"1:2:3:4:5:6:7:8:9-a",  // too many groups
+	}
+
+	for _, spec := range tests {
+		if _, err := ParseTargets(context.Background(), []string{spec}, TargetOptions{}); err == nil {
+			t.Errorf("ParseTargets(%q) succeeded", spec)
+		}
+	}
+}
+
+func TestJoinOctetLists(t *testing.T) {
+	tests := []struct {
+		specs []string
+		want  []string
+	}{
+		{[]string{"10.0.1", "3.1-254"}, []string{"10.0.1,3.1-254"}},
+		{[]string{"192.168.1.1", "5", "9"}, []string{"192.168.1.1,5,9"}},
+		{[]string{"10.0.0.1", "10.0.0.2"}, []string{"10.0.0.1", "10.0.0.2"}},
+		{[]string{"web.test", "5"}, []string{"web.test", "5"}},
+		{[]string{"10.0.0.0/24", "5"}, []string{"10.0.0.0/24", "5"}},
+	}
+
+	for _, tt := range tests {
+		if got := joinOctetLists(tt.specs); !slices.Equal(got, tt.want) {
+			t.Errorf("joinOctetLists(%q) = %q, want %q", tt.specs, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// lookupTimeout bounds how long a single hostname lookup may take
+const lookupTimeout = 10 * time.Second
+
+// Resolver resolves hostnames found in target lists
+type Resolver struct {
+	resolver *net.Resolver
+	network  string
+}
+
+// NewResolver creates a resolver for hostname targets. server is an
+// optional DNS server address ("10.0.0.53" or "127.0.0.1:5353"); when
+// empty the system resolver is used. family selects which records are
+// returned: "4" (A), "6" (AAAA) or "both".
+func NewResolver(server, family string) (*Resolver, error) {
+	r := &Resolver{
+		resolver: net.DefaultResolver,
+	}
+
+	switch family {
+	case "4":
+		r.network = "ip4"
+	case "6":
+		r.network = "ip6"
+	case "", "both":
+		r.network = "ip"
+	default:
+		return nil, fmt.Errorf("invalid address family: %s (valid: 4, 6, both)", family)
+	}
+
+	if server != "" {
+		address, err := dnsServerAddress(server)
+		if err != nil {
+			return nil, err
+		}
+		dialer := net.Dialer{Timeout: lookupTimeout}
+		r.resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, address)
+			},
+		}
+	}
+
+	return r, nil
+}
+
+// dnsServerAddress normalizes a DNS server to host:port form
+func dnsServerAddress(server string) (string, error) {
+	if net.ParseIP(server) != nil {
+		return net.JoinHostPort(server, "53"), nil
+	}
+
+	host, port, err := net.SplitHostPort(server)
+	if err != nil || net.ParseIP(host) == nil || port == "" {
+		return "", fmt.Errorf("invalid DNS server: %s (expected IP or IP:port)", server)
+	}
+	return server, nil
+}
+
+// Lookup returns the addresses of a hostname in the configured family. The
+// lookup is abandoned if ctx ends first.
+func (r *Resolver) Lookup(ctx context.Context, host string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+	defer cancel()
+
+	addrs, err := r.resolver.LookupNetIP(ctx, r.network, host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+
+	ips := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		ips = append(ips, addr.Unmap().String())
+	}
+
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no addresses found for %s", host)
+	}
+
+	return ips, nil
+}
+
+// isHostname reports whether s is a syntactically valid DNS hostname
+func isHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || len(s) > 253 {
+		return false
+	}
+
+	labels := strings.Split(s, ".")
+
+	// A numeric final label means a malformed IP address, not a name
+	if strings.Trim(labels[len(labels)-1], "0123456789") == "" {
+		return false
+	}
+
+	for _, label := range labels {
+		if label == "" || len(label) > 63 {
+			return false
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			isAlnum := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+			if !isAlnum && c != '-' && c != '_' {
+				return false
+			}
+		}
+	}
+	return true
+}
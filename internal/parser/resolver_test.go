@@ -0,0 +1,241 @@
+package parser
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// DNS record types answered by the stand-in server
+const (
+	typeA    = 1
+	typeAAAA = 28
+)
+
+// standInDNS serves the given names' addresses over UDP on loopback,
+// answering NXDOMAIN for anything else, and stays silent when records is
+// nil. It returns the server's address.
+func standInDNS(t *testing.T, records map[string][]string) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if records == nil {
+				continue
+			}
+			if reply := dnsReply(buf[:n], records); reply != nil {
+				conn.WriteTo(reply, addr)
+			}
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+// dnsReply builds the response to a single-question DNS query
+func dnsReply(query []byte, records map[string][]string) []byte {
+	if len(query) < 12 {
+		return nil
+	}
+
+	// Walk the question's name to find where the question ends
+	var labels []string
+	off := 12
+	for off < len(query) && query[off] != 0 {
+		n := int(query[off])
+		if off+1+n > len(query) {
+			return nil
+		}
+		labels = append(labels, string(query[off+1:off+1+n]))
+		off += 1 + n
+	}
+	off++
+	if off+4 > len(query) {
+		return nil
+	}
+	qtype := binary.BigEndian.Uint16(query[off:])
+	question := query[12 : off+4]
+	name := strings.ToLower(strings.Join(labels, "."))
+
+	addrs, ok := records[name]
+	var answers [][]byte
+	for _, s := range addrs {
+		addr := netip.MustParseAddr(s)
+		if (qtype == typeA) != addr.Is4() || (qtype != typeA && qtype != typeAAAA) {
+			continue
+		}
+		rr := []byte{0xc0, 12} // pointer to the question's name
+		rr = binary.BigEndian.AppendUint16(rr, qtype)
+		rr = binary.BigEndian.AppendUint16(rr, 1) // class IN
+		rr = binary.BigEndian.AppendUint32(rr, 60)
+		rr = binary.BigEndian.AppendUint16(rr, uint16(addr.BitLen()/8))
+		answers = append(answers, append(rr, addr.AsSlice()...))
+	}
+
+	flags := uint16(0x8180) // response, recursion desired and available
+	if !ok {
+		flags |= 3 // NXDOMAIN
+	}
+
+	reply := append([]byte(nil), query[:2]...)
+	reply = binary.BigEndian.AppendUint16(reply, flags)
+	reply = binary.BigEndian.AppendUint16(reply, 1)
+	reply = binary.BigEndian.AppendUint16(reply, uint16(len(answers)))
+	reply = binary.BigEndian.AppendUint32(reply, 0)
+	reply = append(reply, question...)
+	for _, rr := range answers {
+		reply = append(reply, rr...)
+	}
+	return reply
+}
+
+func TestResolverLookup(t *testing.T) {
+	server := standInDNS(t, map[string][]string{
+		"db01.test":   {"10.0.0.5", "fd00::5"},
+		"v4only.test": {"10.0.0.6"},
+	})
+
+	tests := []struct {
+		name    string
+		host    string
+		family  string
+		want    []string
+		wantErr bool
+	}{
+		{"IPv4", "db01.test", "4", []string{"10.0.0.5"}, false},
+		{"IPv6", "db01.test", "6", []string{"fd00::5"}, false},
+		{"both", "db01.test", "both", []string{"10.0.0.5", "fd00::5"}, false},
+		{"missing family", "v4only.test", "6", nil, true},
+		{"unknown", "nope.test", "both", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewResolver(server, tt.family)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := r.Lookup(context.Background(), tt.host)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Lookup(%s) error = %v, want error = %v", tt.host, err, tt.wantErr)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Lookup(%s) = %v, want %v", tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolverLookupCancel(t *testing.T) {
+	r, err := NewResolver(standInDNS(t, nil), "4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := r.Lookup(ctx, "slow.test"); err == nil {
+		t.Fatal("Lookup succeeded against a silent server")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Lookup took %v after its context ended", elapsed)
+	}
+}
+
+func TestParseTargetsHostname(t *testing.T) {
+	r, err := NewResolver(standInDNS(t, map[string][]string{
+		"web.test": {"192.0.2.10", "192.0.2.11"},
+	}), "4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set, err := ParseTargets(context.Background(), []string{"web.test", "192.0.2.11"}, TargetOptions{Resolver: r})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The literal address repeats one the hostname resolved to
+	var got []Target
+	for it := set.Iterator(); ; {
+		target, ok := it.Next()
+		if !ok {
+			break
+		}
+		got = append(got, target)
+	}
+	slices.SortFunc(got, func(a, b Target) int { return strings.Compare(a.IP, b.IP) })
+
+	want := []Target{{IP: "192.0.2.10", Hostname: "web.test"}, {IP: "192.0.2.11", Hostname: "web.test"}}
+	if !slices.Equal(got, want) {
+		t.Errorf("targets = %v, want %v", got, want)
+	}
+}
+
+func TestNewResolver(t *testing.T) {
+	tests := []struct {
+		server  string
+		family  string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"10.0.0.53", "4", false},
+		{"127.0.0.1:5353", "both", false},
+		{"[fd00::53]:53", "6", false},
+		{"dns.example", "4", true},
+		{"10.0.0.53:", "4", true},
+		{"", "7", true},
+	}
+
+	for _, tt := range tests {
+		_, err := NewResolver(tt.server, tt.family)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewResolver(%q, %q) error = %v, want error = %v", tt.server, tt.family, err, tt.wantErr)
+		}
+	}
+}
+
+func TestIsHostname(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{"example.com", true},
+		{"db01.internal.", true},
+		{"_sip._tcp.example.com", true},
+		{"localhost", true},
+		{"10.0.0.256", false},
+		{"-bad.example", false},
+		{"bad-.example", false},
+		{"a..b", false},
+		{"under score!", false},
+		{strings.Repeat("a", 64) + ".com", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := isHostname(tt.s); got != tt.want {
+			t.Errorf("isHostname(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
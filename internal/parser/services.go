@@ -0,0 +1,137 @@
+package parser
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// servicesFile maps service names to ports and ranks ports by how often
+// they are found open
+//
+//go:embed services.txt
+var servicesFile string
+
+// serviceTable indexes the embedded services table
+type serviceTable struct {
+	// byName maps protocol, then lowercase service name or alias, to ports
+	byName map[string]map[string][]int
+
+	// ranked lists each protocol's ranked ports, most often open first
+	ranked map[string][]int
+}
+
+// services parses the embedded table on first use
+var services = sync.OnceValues(func() (*serviceTable, error) {
+	return parseServices(servicesFile)
+})
+
+// parseServices reads a services table: one "name port/protocol rank
+// [aliases...]" entry per line, with "-" for unranked ports
+func parseServices(data string) (*serviceTable, error) {
+	table := &serviceTable{
+		byName: make(map[string]map[string][]int),
+		ranked: make(map[string][]int),
+	}
+
+	type rankedPort struct {
+		port, rank int
+	}
+	ranks := make(map[string][]rankedPort)
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("services line %d: expected name port/protocol rank", lineNum)
+		}
+
+		portStr, protocol, ok := strings.Cut(fields[1], "/")
+		port, err := strconv.Atoi(portStr)
+		if !ok || err != nil || validatePort(port) != nil {
+			return nil, fmt.Errorf("services line %d: invalid port %s", lineNum, fields[1])
+		}
+
+		if fields[2] != "-" {
+			rank, err := strconv.Atoi(fields[2])
+			if err != nil || rank < 1 {
+				return nil, fmt.Errorf("services line %d: invalid rank %s", lineNum, fields[2])
+			}
+			ranks[protocol] = append(ranks[protocol], rankedPort{port: port, rank: rank})
+		}
+
+		names := table.byName[protocol]
+		if names == nil {
+			names = make(map[string][]int)
+			table.byName[protocol] = names
+		}
+		add := func(name string) {
+			name = strings.ToLower(name)
+			names[name] = append(names[name], port)
+		}
+		if fields[0] != "unknown" {
+			add(fields[0])
+		}
+		for _, alias := range fields[3:] {
+			add(alias)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for protocol, ports := range ranks {
+		sort.Slice(ports, func(i, j int) bool { return ports[i].rank < ports[j].rank })
+		for _, p := range ports {
+			table.ranked[protocol] = append(table.ranked[protocol], p.port)
+		}
+	}
+
+	return table, nil
+}
+
+// topPorts returns the n ports of a protocol most often found open
+func topPorts(n int, protocol string) ([]int, error) {
+	table, err := services()
+	if err != nil {
+		return nil, err
+	}
+
+	ranked := table.ranked[protocol]
+	if n < 1 {
+		return nil, fmt.Errorf("invalid port set top%d: must select at least one port", n)
+	}
+	if n > len(ranked) {
+		return nil, fmt.Errorf("invalid port set top%d: only %d %s ports are ranked", n, len(ranked), protocol)
+	}
+	return ranked[:n], nil
+}
+
+// servicePorts returns the ports of a protocol known by a service name
+func servicePorts(name, protocol string) ([]int, error) {
+	table, err := services()
+	if err != nil {
+		return nil, err
+	}
+
+	name = strings.ToLower(name)
+	if ports, ok := table.byName[protocol][name]; ok {
+		return ports, nil
+	}
+
+	for other, names := range table.byName {
+		if _, ok := names[name]; ok && other != protocol {
+			return nil, fmt.Errorf("service %s has no %s port", name, protocol)
+		}
+	}
+	return nil, fmt.Errorf("unknown port or service: %s", name)
+}
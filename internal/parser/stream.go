@@ -0,0 +1,123 @@
+package parser
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+)
+
+// SpecReader reads target specifications from a list such as an asset
+// inventory. Lines may hold several specifications separated by commas or
+// whitespace; anything after "#" is a comment.
+type SpecReader struct {
+	scanner *bufio.Scanner
+	pending []string
+	line    int
+}
+
+// NewSpecReader creates a reader over a target list
+func NewSpecReader(r io.Reader) *SpecReader {
+	return &SpecReader{scanner: bufio.NewScanner(r)}
+}
+
+// Next returns the next specification, or false at the end of the list
+func (sr *SpecReader) Next() (string, bool) {
+	for len(sr.pending) == 0 {
+		if !sr.scanner.Scan() {
+			return "", false
+		}
+		sr.line++
+
+		line, _, _ := strings.Cut(sr.scanner.Text(), "#")
+		for _, field := range strings.Fields(line) {
+			for _, spec := range joinOctetLists(strings.Split(field, ",")) {
+				if spec != "" {
+					sr.pending = append(sr.pending, spec)
+				}
+			}
+		}
+	}
+
+	spec := sr.pending[0]
+	sr.pending = sr.pending[1:]
+	return spec, true
+}
+
+// Line returns the line number of the specification last returned
+func (sr *SpecReader) Line() int {
+	return sr.line
+}
+
+// Err returns the first error encountered while reading
+func (sr *SpecReader) Err() error {
+	return sr.scanner.Err()
+}
+
+// ReadSpecs reads every specification from a target list
+func ReadSpecs(r io.Reader) ([]string, error) {
+	sr := NewSpecReader(r)
+	var specs []string
+	for {
+		spec, ok := sr.Next()
+		if !ok {
+			break
+		}
+		specs = append(specs, spec)
+	}
+	return specs, sr.Err()
+}
+
+// TargetStream expands specifications from a SpecReader as they are
+// needed, so a target list is never held in memory. Specifications that
+// fail to parse or resolve are passed to OnError and skipped.
+type TargetStream struct {
+	ctx   context.Context
+	specs *SpecReader
+	opts  TargetOptions
+	block Block
+	index uint64
+
+	// OnError is called for each specification that is skipped
+	OnError func(line int, spec string, err error)
+}
+
+// NewTargetStream creates a stream over the specifications in specs. The
+// stream ends early, without reporting further entries, once ctx ends.
+func NewTargetStream(ctx context.Context, specs *SpecReader, opts TargetOptions) *TargetStream {
+	return &TargetStream{ctx: ctx, specs: specs, opts: opts}
+}
+
+// Err returns the first error encountered while reading the list
+func (ts *TargetStream) Err() error {
+	return ts.specs.Err()
+}
+
+// Next returns the next target, or false once the list is exhausted
+func (ts *TargetStream) Next() (Target, bool) {
+	for ts.block == nil || ts.index >= ts.block.Len() {
+		if ts.ctx.Err() != nil {
+			return Target{}, false
+		}
+		spec, ok := ts.specs.Next()
+		if !ok {
+			return Target{}, false
+		}
+
+		block, err := parseTarget(ts.ctx, spec, ts.opts)
+		if ts.ctx.Err() != nil {
+			return Target{}, false
+		}
+		if err != nil {
+			if ts.OnError != nil {
+				ts.OnError(ts.specs.Line(), spec, err)
+			}
+			continue
+		}
+		ts.block, ts.index = block, 0
+	}
+
+	t := ts.block.At(ts.index)
+	ts.index++
+	return t, true
+}
@@ -0,0 +1,78 @@
+package parser
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadSpecs(t *testing.T) {
+	tests := []struct {
+		name string
+		list string
+		want []string
+	}{
+		{"one per line", "10.0.0.1\n10.0.0.2\n", []string{"10.0.0.1", "10.0.0.2"}},
+		{"commas and spaces", "10.0.0.1, 10.0.0.2\t10.0.0.3,,", []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}},
+		{"comments", "# inventory\n10.0.0.1 # gateway\n\n", []string{"10.0.0.1"}},
+		{"octet lists", "10.0.0.1,2,5-6", []string{"10.0.0.1,2,5-6"}},
+		{"CRLF", "10.0.0.1\r\nweb.test\r\n", []string{"10.0.0.1", "web.test"}},
+		{"empty", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadSpecs(strings.NewReader(tt.list))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ReadSpecs = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetStream(t *testing.T) {
+	list := "10.0.0.1\n10.0.0.999 10.0.0.0/33\n10.0.1.0/30\n10.0.2.1-2\n"
+
+	var skipped []int
+	stream := NewTargetStream(context.Background(), NewSpecReader(strings.NewReader(list)), TargetOptions{})
+	stream.OnError = func(line int, spec string, err error) {
+		skipped = append(skipped, line)
+	}
+
+	var got []string
+	for {
+		target, ok := stream.Next()
+		if !ok {
+			break
+		}
+		got = append(got, target.IP)
+	}
+
+	want := []string{"10.0.0.1", "10.0.1.1", "10.0.1.2", "10.0.2.1", "10.0.2.2"}
+	if !slices.Equal(got, want) {
+		t.Errorf("targets = %v, want %v", got, want)
+	}
+	if !slices.Equal(skipped, []int{2, 2}) {
+		t.Errorf("skipped lines = %v, want [2 2]", skipped)
+	}
+	if err := stream.Err(); err != nil {
+		t.Errorf("Err() = %v", err)
+	}
+}
+
+func TestTargetStreamCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := NewTargetStream(ctx, NewSpecReader(strings.NewReader("10.0.0.1\n10.0.0.2\n")), TargetOptions{})
+
+	if _, ok := stream.Next(); !ok {
+		t.Fatal("no target before cancellation")
+	}
+	cancel()
+	if target, ok := stream.Next(); ok {
+		t.Errorf("Next() = %v after cancellation", target)
+	}
+}
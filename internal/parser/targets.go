@@ -0,0 +1,359 @@
+package parser
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"net/netip"
+	"slices"
+	"sort"
+)
+
+// Block is an indexable set of targets produced by a single target
+// specification. Addresses are computed on demand from the index, so a
+// block costs the same memory whether it holds one address or 2^64.
+type Block interface {
+	// Len returns the number of targets, saturating at math.MaxUint64
+	Len() uint64
+
+	// At returns the i-th target, for 0 <= i < Len()
+	At(i uint64) Target
+}
+
+// listBlock holds explicitly enumerated targets, such as the addresses a
+// hostname resolved to
+type listBlock []Target
+
+func (b listBlock) Len() uint64 {
+	return uint64(len(b))
+}
+
+func (b listBlock) At(i uint64) Target {
+	return b[i]
+}
+
+// rangeBlock walks consecutive addresses starting at first
+type rangeBlock struct {
+	first netip.Addr
+	count uint64
+}
+
+func (b rangeBlock) Len() uint64 {
+	return b.count
+}
+
+func (b rangeBlock) At(i uint64) Target {
+	return Target{IP: addrAdd(b.first, i).String()}
+}
+
+func (b rangeBlock) contains(addr netip.Addr) bool {
+	if addr.Is4() != b.first.Is4() || addr.Less(b.first) {
+		return false
+	}
+	return addrDiff(b.first, addr) < b.count
+}
+
+// sampleBlock picks distinct pseudo-random addresses inside a prefix,
+// starting at first. The i-th address is the i-th position of a seeded
+// permutation of the prefix's addresses, so the sample is reproducible,
+// never repeats an address and needs no memory beyond the prefix itself.
+type sampleBlock struct {
+	first    netip.Addr
+	hostBits int
+	count    uint64
+	seed     uint64
+	perm     *Permutation
+}
+
+func (b sampleBlock) Len() uint64 {
+	return b.count
+}
+
+func (b sampleBlock) At(i uint64) Target {
+	lo := b.perm.At(i)
+
+	// Prefixes holding more than 2^64 addresses take the bits above the
+	// permuted low 64 from a hash of them, which keeps addresses distinct
+	var hi uint64
+	if b.hostBits > 64 {
+		hi, _ = maskLow(splitmix64(b.seed^lo), 0, b.hostBits)
+	}
+	return Target{IP: addrAdd128(b.first, hi, lo).String()}
+}
+
+// span is an inclusive range of values for one octet or IPv6 group
+type span struct {
+	lo, hi uint32
+}
+
+// patternBlock walks every address matching a pattern of per-octet (IPv6:
+// per-group) value ranges, such as 10.0.1-3.1-254. The last octet varies
+// fastest.
+type patternBlock struct {
+	groups [][]span
+	sizes  []uint64
+	width  int // bits per group: 8 for IPv4, 16 for IPv6
+	count  uint64
+}
+
+// newPatternBlock builds a pattern from the sorted, merged spans of each
+// group
+func newPatternBlock(groups [][]span, width int) patternBlock {
+	b := patternBlock{groups: groups, sizes: make([]uint64, len(groups)), width: width, count: 1}
+	for g, spans := range groups {
+		for _, s := range spans {
+			b.sizes[g] += uint64(s.hi-s.lo) + 1
+		}
+		b.count = SatMul(b.count, b.sizes[g])
+	}
+	return b
+}
+
+func (b patternBlock) Len() uint64 {
+	return b.count
+}
+
+func (b patternBlock) At(i uint64) Target {
+	values := make([]uint32, len(b.groups))
+	for g := len(b.groups) - 1; g >= 0; g-- {
+		values[g] = nthValue(b.groups[g], i%b.sizes[g])
+		i /= b.sizes[g]
+	}
+	return Target{IP: b.addr(values).String()}
+}
+
+func (b patternBlock) contains(addr netip.Addr) bool {
+	if addr.Is4() != (b.width == 8) {
+		return false
+	}
+
+	raw := addr.AsSlice()
+	for g, spans := range b.groups {
+		var v uint32
+		if b.width == 8 {
+			v = uint32(raw[g])
+		} else {
+			v = uint32(binary.BigEndian.Uint16(raw[2*g:]))
+		}
+		if !slices.ContainsFunc(spans, func(s span) bool { return s.lo <= v && v <= s.hi }) {
+			return false
+		}
+	}
+	return true
+}
+
+// addr assembles an address from the value of each group
+func (b patternBlock) addr(values []uint32) netip.Addr {
+	if b.width == 8 {
+		var a [4]byte
+		for g, v := range values {
+			a[g] = byte(v)
+		}
+		return netip.AddrFrom4(a)
+	}
+
+	var a [16]byte
+	for g, v := range values {
+		binary.BigEndian.PutUint16(a[2*g:], uint16(v))
+	}
+	return netip.AddrFrom16(a)
+}
+
+// nthValue returns the n-th value covered by a list of spans
+func nthValue(spans []span, n uint64) uint32 {
+	for _, s := range spans {
+		size := uint64(s.hi-s.lo) + 1
+		if n < size {
+			return s.lo + uint32(n)
+		}
+		n -= size
+	}
+	return spans[len(spans)-1].hi
+}
+
+// newPrefixBlock builds the block for a CIDR prefix. Network and broadcast
+// addresses are skipped for IPv4 prefixes larger than /31. Prefixes with
+// more than sampleSize addresses are sampled when sampleSize is non-zero.
+func newPrefixBlock(prefix netip.Prefix, sampleSize, seed uint64) Block {
+	prefix = prefix.Masked()
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+
+	size := uint64(math.MaxUint64)
+	if hostBits < 64 {
+		size = uint64(1) << hostBits
+	}
+
+	first := prefix.Addr()
+	if prefix.Addr().Is4() && size > 2 {
+		first = first.Next()
+		size -= 2
+	}
+
+	if sampleSize > 0 && size > sampleSize {
+		return sampleBlock{
+			first:    first,
+			hostBits: hostBits,
+			count:    sampleSize,
+			seed:     seed,
+			perm:     NewPermutation(size, seed),
+		}
+	}
+
+	return rangeBlock{first: first, count: size}
+}
+
+// TargetSet is an ordered collection of blocks that can be indexed as a
+// single sequence of targets
+type TargetSet struct {
+	blocks  []Block
+	offsets []uint64
+	total   uint64
+}
+
+// NewTargetSet builds a set from explicitly listed targets, such as the
+// hosts found alive by host discovery
+func NewTargetSet(targets []Target) *TargetSet {
+	s := &TargetSet{}
+	s.add(listBlock(targets))
+	return s
+}
+
+// add appends a block to the set, skipping empty blocks
+func (s *TargetSet) add(b Block) {
+	if b.Len() == 0 {
+		return
+	}
+	s.blocks = append(s.blocks, b)
+	s.offsets = append(s.offsets, s.total)
+	s.total = satAdd(s.total, b.Len())
+}
+
+// Len returns the total number of targets, saturating at math.MaxUint64
+func (s *TargetSet) Len() uint64 {
+	return s.total
+}
+
+// At returns the i-th target across all blocks
+func (s *TargetSet) At(i uint64) Target {
+	idx := s.blockIndex(i)
+	return s.blocks[idx].At(i - s.offsets[idx])
+}
+
+// blockIndex finds the block holding the i-th target
+func (s *TargetSet) blockIndex(i uint64) int {
+	// Find the last block whose offset is <= i
+	return sort.Search(len(s.offsets), func(n int) bool {
+		return s.offsets[n] > i
+	}) - 1
+}
+
+// Iterator returns an iterator over the set in order
+func (s *TargetSet) Iterator() *TargetIterator {
+	return &TargetIterator{set: s}
+}
+
+// IteratorAt returns an iterator positioned at the i-th target
+func (s *TargetSet) IteratorAt(i uint64) *TargetIterator {
+	if i >= s.total {
+		return &TargetIterator{set: s, block: len(s.blocks)}
+	}
+	idx := s.blockIndex(i)
+	return &TargetIterator{set: s, block: idx, index: i - s.offsets[idx]}
+}
+
+// TargetIterator walks a TargetSet one target at a time
+type TargetIterator struct {
+	set   *TargetSet
+	block int
+	index uint64
+}
+
+// Next returns the next target, or false when the set is exhausted
+func (it *TargetIterator) Next() (Target, bool) {
+	for it.block < len(it.set.blocks) {
+		b := it.set.blocks[it.block]
+		if it.index < b.Len() {
+			t := b.At(it.index)
+			it.index++
+			return t, true
+		}
+		it.block++
+		it.index = 0
+	}
+	return Target{}, false
+}
+
+// addrAdd returns the address n positions after a
+func addrAdd(a netip.Addr, n uint64) netip.Addr {
+	return addrAdd128(a, 0, n)
+}
+
+// addrAdd128 returns the address (hi<<64 | lo) positions after a,
+// wrapping around the address space
+func addrAdd128(a netip.Addr, hi, lo uint64) netip.Addr {
+	if a.Is4() {
+		b := a.As4()
+		v := binary.BigEndian.Uint32(b[:]) + uint32(lo)
+		binary.BigEndian.PutUint32(b[:], v)
+		return netip.AddrFrom4(b)
+	}
+
+	b := a.As16()
+	aHi := binary.BigEndian.Uint64(b[:8])
+	aLo := binary.BigEndian.Uint64(b[8:])
+	sumLo, carry := bits.Add64(aLo, lo, 0)
+	sumHi, _ := bits.Add64(aHi, hi, carry)
+	binary.BigEndian.PutUint64(b[:8], sumHi)
+	binary.BigEndian.PutUint64(b[8:], sumLo)
+	return netip.AddrFrom16(b).WithZone(a.Zone())
+}
+
+// addrDiff returns the number of positions from first to last, which must
+// be of the same family, saturating at math.MaxUint64
+func addrDiff(first, last netip.Addr) uint64 {
+	a, b := first.As16(), last.As16()
+	lo, borrow := bits.Sub64(binary.BigEndian.Uint64(b[8:]), binary.BigEndian.Uint64(a[8:]), 0)
+	hi, _ := bits.Sub64(binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(a[:8]), borrow)
+	if hi != 0 {
+		return math.MaxUint64
+	}
+	return lo
+}
+
+// maskLow keeps only the low n bits of the 128-bit value (hi<<64 | lo)
+func maskLow(hi, lo uint64, n int) (uint64, uint64) {
+	switch {
+	case n >= 128:
+		return hi, lo
+	case n >= 64:
+		return hi & (uint64(1)<<(n-64) - 1), lo
+	default:
+		return 0, lo & (uint64(1)<<n - 1)
+	}
+}
+
+// splitmix64 is a fast, well-distributed 64-bit mixing function
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// satAdd adds two counts, saturating at math.MaxUint64
+func satAdd(a, b uint64) uint64 {
+	sum, carry := bits.Add64(a, b, 0)
+	if carry != 0 {
+		return math.MaxUint64
+	}
+	return sum
+}
+
+// SatMul multiplies two counts, saturating at math.MaxUint64
+func SatMul(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	if hi != 0 {
+		return math.MaxUint64
+	}
+	return lo
+}
@@ -0,0 +1,163 @@
+package parser
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+// collect returns every target of a set, in order
+func collect(set *TargetSet) []Target {
+	var targets []Target
+	for it := set.Iterator(); ; {
+		t, ok := it.Next()
+		if !ok {
+			return targets
+		}
+		targets = append(targets, t)
+	}
+}
+
+func TestParseTargetsCIDR(t *testing.T) {
+	tests := []struct {
+		spec        string
+		count       uint64
+		first, last string
+	}{
+		{"10.0.0.0/24", 254, "10.0.0.1", "10.0.0.254"},
+		{"10.0.0.7/30", 2, "10.0.0.5", "10.0.0.6"},
+		{"10.0.0.0/31", 2, "10.0.0.0", "10.0.0.1"},
+		{"10.0.0.5/32", 1, "10.0.0.5", "10.0.0.5"},
+		{"2001:db8::/126", 4, "2001:db8::", "2001:db8::3"},
+		{"::/0", math.MaxUint64, "::", "::ffff:ffff:ffff:fffe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			set, err := ParseTargets(context.Background(), []string{tt.spec}, TargetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if set.Len() != tt.count {
+				t.Fatalf("Len() = %d, want %d", set.Len(), tt.count)
+			}
+			if got := set.At(0).IP; got != tt.first {
+				t.Errorf("first = %s, want %s", got, tt.first)
+			}
+			if got := set.At(set.Len() - 1).IP; got != tt.last {
+				t.Errorf("last = %s, want %s", got, tt.last)
+			}
+		})
+	}
+}
+
+func TestParseTargetsDedup(t *testing.T) {
+	tests := []struct {
+		name   string
+		specs  []string
+		sample uint64
+		want   uint64
+	}{
+		{"repeated address", []string{"10.0.0.1", "10.0.0.1"}, 0, 1},
+		{"mapped address", []string{"10.0.0.1", "::ffff:10.0.0.1"}, 0, 1},
+		{"address inside a range", []string{"10.0.0.0/30", "10.0.0.1"}, 0, 2},
+		{"address inside a pattern", []string{"10.0.1-2.5", "10.0.2.5", "10.0.3.5"}, 0, 3},
+		{"repeated range", []string{"10.0.0.0/30", "10.0.0.0/30"}, 0, 2},
+		{"address next to a sample", []string{"10.0.0.0/24", "10.0.0.1"}, 10, 11},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := ParseTargets(context.Background(), tt.specs, TargetOptions{SampleSize: tt.sample})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if set.Len() != tt.want {
+				t.Errorf("Len() = %d, want %d (targets %v)", set.Len(), tt.want, collect(set))
+			}
+		})
+	}
+}
+
+func TestSampleBlock(t *testing.T) {
+	tests := []struct {
+		prefix string
+		sample uint64
+	}{
+		{"10.0.0.0/24", 50},
+		{"10.0.0.0/24", 253},
+		{"10.0.0.0/8", 1000},
+		{"2001:db8::/48", 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			prefix := netip.MustParsePrefix(tt.prefix)
+			block := newPrefixBlock(prefix, tt.sample, 42)
+			if block.Len() != tt.sample {
+				t.Fatalf("Len() = %d, want %d", block.Len(), tt.sample)
+			}
+
+			seen := make(map[netip.Addr]bool)
+			for i := range block.Len() {
+				addr := netip.MustParseAddr(block.At(i).IP)
+				if !prefix.Contains(addr) {
+					t.Fatalf("At(%d) = %s, outside %s", i, addr, prefix)
+				}
+				if seen[addr] {
+					t.Fatalf("At(%d) = %s, repeated", i, addr)
+				}
+				seen[addr] = true
+			}
+
+			// IPv4 samples never pick the network or broadcast address
+			if prefix.Addr().Is4() {
+				raw := prefix.Addr().As4()
+				last := netip.AddrFrom4(raw)
+				if bits := 32 - prefix.Bits(); bits > 0 {
+					n := binary.BigEndian.Uint32(raw[:]) | (1<<bits - 1)
+					last = netip.AddrFrom4([4]byte(binary.BigEndian.AppendUint32(nil, n)))
+				}
+				if seen[prefix.Addr()] || seen[last] {
+					t.Errorf("sample includes %s or %s", prefix.Addr(), last)
+				}
+			}
+
+			// The same seed draws the same sample
+			again := newPrefixBlock(prefix, tt.sample, 42)
+			for i := range block.Len() {
+				if block.At(i) != again.At(i) {
+					t.Fatalf("At(%d) differs between runs with the same seed", i)
+				}
+			}
+		})
+	}
+}
+
+func TestIteratorAt(t *testing.T) {
+	set, err := ParseTargets(context.Background(), []string{"10.0.0.0/29", "10.0.1.1", "10.0.2.1-3"}, TargetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := collect(set)
+	if uint64(len(all)) != set.Len() {
+		t.Fatalf("iterated %d targets, Len() = %d", len(all), set.Len())
+	}
+
+	for i := range set.Len() + 1 {
+		var got []Target
+		for it := set.IteratorAt(i); ; {
+			t, ok := it.Next()
+			if !ok {
+				break
+			}
+			got = append(got, t)
+		}
+		if want := all[i:]; !slices.Equal(got, want) {
+			t.Errorf("IteratorAt(%d) yields %v, want %v", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package result
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// grepWriter buffers results and writes one line per host listing every
+// port, in the style of nmap's greppable output:
+//
+//	Host: 10.0.0.1 (db01)	Ports: 22/open/tcp//ssh//OpenSSH 9.6p1/, 80/closed/tcp/////
+type grepWriter struct {
+	w       io.Writer
+	results []*Result
+}
+
+func (g *grepWriter) WriteResult(r *Result) error {
+	g.results = append(g.results, r)
+	return nil
+}
+
+func (g *grepWriter) Flush(summary Summary) error {
+	if _, err := fmt.Fprintf(g.w, "# netscout scan initiated %s\n", summary.StartTime.Format(time.ANSIC)); err != nil {
+		return err
+	}
+
+	var order []string
+	hosts := make(map[string][]*Result)
+	for _, r := range g.results {
+		if _, ok := hosts[r.IP]; !ok {
+			order = append(order, r.IP)
+		}
+		hosts[r.IP] = append(hosts[r.IP], r)
+	}
+
+	up := 0
+	for _, ip := range order {
+		results := hosts[ip]
+
+		var hostname string
+		alive := false
+		ports := make([]string, 0, len(results))
+		for _, r := range results {
+			if hostname == "" {
+				hostname = r.Hostname
+			}
+			if r.Status == StatusOpen || r.Status == StatusClosed || r.Status == StatusUp {
+				alive = true
+			}
+			if r.Status != StatusUp {
+				ports = append(ports, grepPort(r))
+			}
+		}
+		if alive {
+			up++
+		}
+
+		// Hosts found by discovery alone get nmap's ping scan line
+		line := fmt.Sprintf("Host: %s (%s)\tPorts: %s\n", ip, hostname, strings.Join(ports, ", "))
+		if len(ports) == 0 {
+			line = fmt.Sprintf("Host: %s (%s)\tStatus: Up\n", ip, hostname)
+		}
+		if _, err := io.WriteString(g.w, line); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(g.w, "# netscout done at %s -- %d IP addresses (%d hosts up) scanned in %.2f seconds\n",
+		summary.EndTime.Format(time.ANSIC), len(order), up, summary.Duration.Seconds())
+	return err
+}
+
+// grepPort renders a result as port/state/protocol/owner/service/rpc/version/
+func grepPort(r *Result) string {
+	service := r.Service
+	if service != "" && r.TLS != nil {
+		service = "ssl|" + service
+	}
+	version := strings.TrimSpace(r.Product + " " + r.Version)
+
+	return fmt.Sprintf("%d/%s/%s//%s//%s/", r.Port, r.Status, r.Protocol, grepField(service), grepField(version))
+}
+
+// grepField keeps a value from breaking the line's field separators
+func grepField(s string) string {
+	return strings.NewReplacer("/", "|", ",", " ", "\t", " ", "\n", " ").Replace(s)
+}
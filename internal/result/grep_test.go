@@ -0,0 +1,100 @@
+package result
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGrepWriter(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []*Result
+		hosts   []string
+		up      int
+	}{
+		{
+			name: "one line per host",
+			results: []*Result{
+				{IP: "10.0.0.1", Hostname: "db01", Port: 22, Protocol: "tcp", Status: StatusOpen, Service: "ssh", Product: "OpenSSH", Version: "9.6p1"},
+				{IP: "10.0.0.1", Hostname: "db01", Port: 80, Protocol: "tcp", Status: StatusClosed},
+				{IP: "10.0.0.2", Port: 53, Protocol: "udp", Status: StatusOpenFiltered},
+			},
+			hosts: []string{
+				"Host: 10.0.0.1 (db01)\tPorts: 22/open/tcp//ssh//OpenSSH 9.6p1/, 80/closed/tcp/////",
+				"Host: 10.0.0.2 ()\tPorts: 53/open|filtered/udp/////",
+			},
+			up: 1,
+		},
+		{
+			name: "TLS service",
+			results: []*Result{
+				{IP: "10.0.0.1", Port: 443, Protocol: "tcp", Status: StatusOpen, Service: "http", TLS: &TLSInfo{Version: "TLS 1.3"}},
+			},
+			hosts: []string{"Host: 10.0.0.1 ()\tPorts: 443/open/tcp//ssl|http///"},
+			up:    1,
+		},
+		{
+			name: "separators in fields",
+			results: []*Result{
+				{IP: "10.0.0.1", Port: 8080, Protocol: "tcp", Status: StatusOpen, Service: "http", Product: "Apache/2.4, mod_ssl"},
+			},
+			hosts: []string{"Host: 10.0.0.1 ()\tPorts: 8080/open/tcp//http//Apache|2.4  mod_ssl/"},
+			up:    1,
+		},
+		{
+			name: "discovered hosts",
+			results: []*Result{
+				{IP: "10.0.0.1", Hostname: "db01", Protocol: "icmp", Status: StatusUp},
+				{IP: "10.0.0.2", Port: 22, Protocol: "tcp", Status: StatusUp},
+				{IP: "10.0.0.2", Port: 22, Protocol: "tcp", Status: StatusFiltered},
+			},
+			hosts: []string{
+				"Host: 10.0.0.1 (db01)\tStatus: Up",
+				"Host: 10.0.0.2 ()\tPorts: 22/filtered/tcp/////",
+			},
+			up: 2,
+		},
+		{name: "no results", up: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, "grep")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range tt.results {
+				if err := w.WriteResult(r); err != nil {
+					t.Fatal(err)
+				}
+			}
+			start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+			if err := w.Flush(Summary{StartTime: start, EndTime: start.Add(2 * time.Second), Duration: 2 * time.Second}); err != nil {
+				t.Fatal(err)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+			if len(lines) != len(tt.hosts)+2 {
+				t.Fatalf("got %d lines, want %d:\n%s", len(lines), len(tt.hosts)+2, buf.String())
+			}
+			if want := "# netscout scan initiated Wed May  1 12:00:00 2024"; lines[0] != want {
+				t.Errorf("header = %q, want %q", lines[0], want)
+			}
+			for i, want := range tt.hosts {
+				if lines[i+1] != want {
+					t.Errorf("line %d = %q, want %q", i+1, lines[i+1], want)
+				}
+			}
+			footer := lines[len(lines)-1]
+			wantFooter := "# netscout done at Wed May  1 12:00:02 2024 -- " +
+				strconv.Itoa(len(tt.hosts)) + " IP addresses (" + strconv.Itoa(tt.up) + " hosts up) scanned in 2.00 seconds"
+			if footer != wantFooter {
+				t.Errorf("footer = %q, want %q", footer, wantFooter)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package result
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Report is the document written by the json format
+type Report struct {
+	Summary Summary   `json:"summary"`
+	Results []*Result `json:"results"`
+}
+
+// LoadReport reads a results file written with the json format
+func LoadReport(path string) (*Report, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open results: %w", err)
+	}
+	defer f.Close()
+
+	report, err := ReadReport(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return report, nil
+}
+
+// ReadReport decodes a json format results document
+func ReadReport(r io.Reader) (*Report, error) {
+	var report Report
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return nil, fmt.Errorf("failed to parse results: %w", err)
+	}
+
+	if report.Results == nil {
+		return nil, fmt.Errorf("not a netscout json results file")
+	}
+
+	// Files from before UDP scanning carry no protocol; every port in them
+	// was scanned over TCP
+	for _, r := range report.Results {
+		if r.Protocol == "" && r.Status != StatusUp {
+			r.Protocol = "tcp"
+		}
+	}
+
+	return &report, nil
+}
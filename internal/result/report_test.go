@@ -0,0 +1,107 @@
+package result
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReportRoundTrip(t *testing.T) {
+	mmh3 := int32(-1234)
+	results := []*Result{
+		{
+			IP: "10.0.0.1", Hostname: "web.test", Port: 443, Protocol: "tcp",
+			Status: StatusOpen, Reason: ReasonSynAck,
+			Timestamp: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), Duration: 3 * time.Millisecond,
+			Service: "https", Product: "nginx", Version: "1.25.3", Attempts: 2,
+			TLS:  &TLSInfo{Version: "TLS 1.3", CipherSuite: "TLS_AES_128_GCM_SHA256", SupportedVersions: []string{"TLS 1.2", "TLS 1.3"}},
+			HTTP: &HTTPInfo{URL: "https://web.test/", StatusCode: 200, Title: "Home", FaviconMMH3: &mmh3},
+		},
+		{IP: "10.0.0.1", Port: 53, Protocol: "udp", Status: StatusOpenFiltered, Reason: ReasonNoResponse},
+		{IP: "10.0.0.2", Protocol: "icmp", Status: StatusUp, Reason: ReasonEchoReply},
+	}
+	summary := Summary{TotalScanned: 2, OpenPorts: 1, OpenFiltered: 1, HostsUp: 2}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if err := w.WriteResult(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(summary); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "scan.json")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	report, err := LoadReport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(report.Results, results) {
+		t.Errorf("results changed in the round trip:\ngot  %+v\nwant %+v", report.Results, results)
+	}
+	if report.Summary != summary {
+		t.Errorf("summary = %+v, want %+v", report.Summary, summary)
+	}
+}
+
+func TestReadReport(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		protocols []string
+		wantErr   bool
+	}{
+		{
+			name:      "current",
+			data:      `{"summary": {}, "results": [{"ip": "10.0.0.1", "port": 53, "protocol": "udp", "status": "open"}]}`,
+			protocols: []string{"udp"},
+		},
+		{
+			// Written before results named their protocol
+			name:      "no protocol",
+			data:      `{"summary": {"TotalScanned": 1}, "results": [{"ip": "10.0.0.1", "port": 22, "status": "open", "timestamp": "2024-05-01T12:00:00Z", "duration": 1000}]}`,
+			protocols: []string{"tcp"},
+		},
+		{
+			name:      "discovered host",
+			data:      `{"summary": {}, "results": [{"ip": "10.0.0.1", "port": 0, "protocol": "", "status": "up"}]}`,
+			protocols: []string{""},
+		},
+		{name: "empty", data: `{"summary": {}, "results": []}`, protocols: []string{}},
+		{name: "not a results file", data: `{"version": 3, "config": {}}`, wantErr: true},
+		{name: "malformed", data: `{"results": [`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := ReadReport(strings.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadReport error = %v, want error = %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			protocols := make([]string, 0)
+			for _, r := range report.Results {
+				protocols = append(protocols, r.Protocol)
+			}
+			if !reflect.DeepEqual(protocols, tt.protocols) {
+				t.Errorf("protocols = %q, want %q", protocols, tt.protocols)
+			}
+		})
+	}
+}
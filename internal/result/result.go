@@ -1,8 +1,6 @@
 package result
 
 import (
-	"encoding/csv"
-	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -15,20 +13,118 @@ import (
 type Status string
 
 const (
-	StatusOpen     Status = "open"
-	StatusClosed   Status = "closed"
-	StatusFiltered Status = "filtered"
-	StatusError    Status = "error"
+	StatusOpen         Status = "open"
+	StatusClosed       Status = "closed"
+	StatusFiltered     Status = "filtered"
+	StatusOpenFiltered Status = "open|filtered"
+	StatusError        Status = "error"
+
+	// StatusUp marks a host found alive by host discovery; Protocol names
+	// the probe it answered (tcp, icmp or arp) and Port the TCP port
+	StatusUp Status = "up"
+)
+
+// Reasons a result was given its status, in nmap's terms
+const (
+	ReasonSynAck      = "syn-ack"
+	ReasonReset       = "reset"
+	ReasonConnRefused = "conn-refused"
+	ReasonNoResponse  = "no-response"
+	ReasonHostUnreach = "host-unreach"
+	ReasonNetUnreach  = "net-unreach"
+	ReasonPortUnreach = "port-unreach"
+	ReasonUDPResponse = "udp-response"
+	ReasonEchoReply   = "echo-reply"
+	ReasonARPResponse = "arp-response"
+	ReasonDNSError    = "dns-error"
+	ReasonError       = "error"
+
+	// ReasonLocalError marks probes that failed on the scanning host, such
+	// as when it ran out of file descriptors, and say nothing of the target
+	ReasonLocalError = "local-error"
 )
 
 // Result represents a single scan result
 type Result struct {
 	IP        string        `json:"ip"`
+	Hostname  string        `json:"hostname,omitempty"`
 	Port      int           `json:"port"`
+	Protocol  string        `json:"protocol"`
 	Status    Status        `json:"status"`
+	Reason    string        `json:"reason,omitempty"`
 	Timestamp time.Time     `json:"timestamp"`
 	Duration  time.Duration `json:"duration"`
 	Error     string        `json:"error,omitempty"`
+	Banner    string        `json:"banner,omitempty"`
+	Service   string        `json:"service,omitempty"`
+	Product   string        `json:"product,omitempty"`
+	Version   string        `json:"version,omitempty"`
+	CPE       string        `json:"cpe,omitempty"`
+	TLS       *TLSInfo      `json:"tls,omitempty"`
+	HTTP      *HTTPInfo     `json:"http,omitempty"`
+
+	// Attempts is how many probes it took to reach the status
+	Attempts int `json:"attempts,omitempty"`
+
+	// Seq is the position of the originating task in the scan's task stream
+	Seq uint64 `json:"-"`
+}
+
+// HTTPInfo describes the response of a web server on a port
+type HTTPInfo struct {
+	URL           string   `json:"url"`
+	StatusCode    int      `json:"status_code"`
+	Title         string   `json:"title,omitempty"`
+	Server        string   `json:"server,omitempty"`
+	PoweredBy     string   `json:"powered_by,omitempty"`
+	ContentType   string   `json:"content_type,omitempty"`
+	ContentLength int64    `json:"content_length"`
+	Redirects     []string `json:"redirects,omitempty"`
+	FaviconMMH3   *int32   `json:"favicon_mmh3,omitempty"`
+	FaviconSHA256 string   `json:"favicon_sha256,omitempty"`
+}
+
+// TLSInfo describes the TLS configuration observed on a port
+type TLSInfo struct {
+	Version           string        `json:"version"`
+	CipherSuite       string        `json:"cipher_suite"`
+	SupportedVersions []string      `json:"supported_versions,omitempty"`
+	Certificates      []Certificate `json:"certificates,omitempty"`
+}
+
+// Certificate summarizes an X.509 certificate presented by a server
+type Certificate struct {
+	Subject            string    `json:"subject"`
+	SANs               []string  `json:"sans,omitempty"`
+	Issuer             string    `json:"issuer"`
+	Serial             string    `json:"serial"`
+	NotBefore          time.Time `json:"not_before"`
+	NotAfter           time.Time `json:"not_after"`
+	KeyType            string    `json:"key_type"`
+	KeySize            int       `json:"key_size,omitempty"`
+	SignatureAlgorithm string    `json:"signature_algorithm"`
+	SelfSigned         bool      `json:"self_signed"`
+}
+
+// Leaf returns the server's own certificate, or nil if none was presented
+func (t *TLSInfo) Leaf() *Certificate {
+	if t == nil || len(t.Certificates) == 0 {
+		return nil
+	}
+	return &t.Certificates[0]
+}
+
+// Filter decides whether a result is kept for output
+type Filter func(r *Result) bool
+
+// ExpiringWithin keeps results whose leaf certificate expires within d
+// of now, including certificates that have already expired
+func ExpiringWithin(d time.Duration) Filter {
+	cutoff := time.Now().Add(d)
+	return func(r *Result) bool {
+		leaf := r.TLS.Leaf()
+		return leaf != nil && leaf.NotAfter.Before(cutoff)
+	}
 }
 
 // Summary contains aggregated scan statistics
@@ -37,10 +133,77 @@ type Summary struct {
 	OpenPorts    int
 	ClosedPorts  int
 	Filtered     int
+	OpenFiltered int
 	Errors       int
-	Duration     time.Duration
-	StartTime    time.Time
-	EndTime      time.Time
+
+	// HostsUp and HostsDown count the hosts that did and did not answer
+	// host discovery; both are zero when discovery was skipped
+	HostsUp   int
+	HostsDown int
+
+	// ScanType is how ports were probed (tcp connect, syn or udp)
+	ScanType string `json:",omitempty"`
+
+	Duration  time.Duration
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// Options controls where and how a Collector writes results
+type Options struct {
+	// OutputFile is the path results are written to (empty = stdout)
+	OutputFile string
+
+	// Format is the output format (text, json, csv, ndjson, xml, grep)
+	Format string
+
+	// OutputBase additionally writes the text, grep, json and xml formats
+	// to files named after it (empty = disabled)
+	OutputBase string
+
+	// Verbose prints open ports as they are found in text mode
+	Verbose bool
+
+	// Stream writes each result as it arrives rather than once the scan
+	// completes, for every output whose format CanStream accepts
+	Stream bool
+
+	// DropClosed keeps only open and filtered results in memory. Closed
+	// and errored ports still count towards the summary.
+	DropClosed bool
+
+	// ScanType is the scan type recorded in the summary (tcp, syn, udp)
+	ScanType string
+}
+
+// baseOutputs lists the formats written for Options.OutputBase, with the
+// extension appended to the base name for each
+var baseOutputs = []struct {
+	format    string
+	extension string
+}{
+	{"text", ".txt"},
+	{"grep", ".gnmap"},
+	{"json", ".json"},
+	{"xml", ".xml"},
+}
+
+// sink is one destination results are written to
+type sink struct {
+	file      *os.File // nil when writing to stdout
+	writer    Writer
+	streaming bool
+	err       error
+}
+
+// write passes a result to the sink's writer, remembering the first failure
+func (s *sink) write(r *Result) {
+	if s.err != nil {
+		return
+	}
+	if err := s.writer.WriteResult(r); err != nil {
+		s.err = fmt.Errorf("failed to write result: %w", err)
+	}
 }
 
 // Collector collects and manages scan results
@@ -50,41 +213,102 @@ type Collector struct {
 	summary    Summary
 	resultChan chan *Result
 	doneChan   chan struct{}
-	writer     io.Writer
-	format     string
-	verbose    bool
+	sinks      []*sink
+	dropClosed bool
+	filter     Filter
 }
 
 // NewCollector creates a new result collector
-func NewCollector(outputFile, format string, verbose bool) (*Collector, error) {
-	var writer io.Writer = os.Stdout
-
-	if outputFile != "" {
-		f, err := os.Create(outputFile)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create output file: %w", err)
-		}
-		writer = f
-	}
-
+func NewCollector(opts Options) (*Collector, error) {
 	c := &Collector{
 		results:    make([]*Result, 0),
 		resultChan: make(chan *Result, 1000), // Buffered to prevent blocking
 		doneChan:   make(chan struct{}),
-		writer:     writer,
-		format:     format,
-		verbose:    verbose,
+		dropClosed: opts.DropClosed,
 		summary: Summary{
 			StartTime: time.Now(),
+			ScanType:  opts.ScanType,
 		},
 	}
 
+	primary, err := c.addSink(opts.OutputFile, opts.Format, opts.Stream)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verbose text output has always printed open ports as they are found
+	if opts.Format == "text" && opts.Verbose {
+		var out io.Writer = os.Stdout
+		if primary.file != nil {
+			out = primary.file
+		}
+		primary.writer = &textWriter{w: out, prefix: "[+] "}
+		primary.streaming = true
+	}
+
+	if opts.OutputBase != "" {
+		for _, o := range baseOutputs {
+			if _, err := c.addSink(opts.OutputBase+o.extension, o.format, opts.Stream); err != nil {
+				c.closeSinks()
+				return nil, err
+			}
+		}
+	}
+
 	// Start the collector goroutine
 	go c.collect()
 
 	return c, nil
 }
 
+// addSink opens an output in the given format, writing to stdout when path
+// is empty. Formats that cannot be streamed are always buffered.
+func (c *Collector) addSink(path, format string, stream bool) (*sink, error) {
+	var out io.Writer = os.Stdout
+	var file *os.File
+
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create output file: %w", err)
+		}
+		out = f
+		file = f
+	}
+
+	writer, err := NewWriter(out, format)
+	if err != nil {
+		if file != nil {
+			file.Close()
+		}
+		return nil, err
+	}
+
+	s := &sink{
+		file:      file,
+		writer:    writer,
+		streaming: stream && CanStream(format),
+	}
+	c.sinks = append(c.sinks, s)
+	return s, nil
+}
+
+// closeSinks closes every output file
+func (c *Collector) closeSinks() {
+	for _, s := range c.sinks {
+		if s.file != nil {
+			s.file.Close()
+		}
+	}
+}
+
+// SetFilter restricts which results are kept for output. Filtered-out
+// results still count towards the summary. Must be called before any
+// results are submitted.
+func (c *Collector) SetFilter(f Filter) {
+	c.filter = f
+}
+
 // Submit submits a result to the collector
 func (c *Collector) Submit(r *Result) {
 	c.resultChan <- r
@@ -93,21 +317,96 @@ func (c *Collector) Submit(r *Result) {
 // collect runs in a goroutine and processes results
 func (c *Collector) collect() {
 	for r := range c.resultChan {
-		c.mu.Lock()
+		c.Add(r)
+	}
+	close(c.doneChan)
+}
+
+// Add records a result synchronously. It is equivalent to Submit but
+// returns only once the result is reflected in the summary and results.
+func (c *Collector) Add(r *Result) {
+	keep := c.filter == nil || c.filter(r)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.updateSummary(r)
+	if !keep {
+		return
+	}
+
+	c.stream(r)
+
+	if c.retain(r) {
 		c.results = append(c.results, r)
-		c.updateSummary(r)
-		c.mu.Unlock()
+	}
+}
 
-		// Print result immediately in verbose mode (text format only)
-		if c.verbose && c.format == "text" {
-			c.printResult(r)
+// stream writes a result to every output that is written as results arrive
+func (c *Collector) stream(r *Result) {
+	for _, s := range c.sinks {
+		if s.streaming {
+			s.write(r)
 		}
 	}
-	close(c.doneChan)
+}
+
+// retain reports whether a result is held in memory until the scan ends
+func (c *Collector) retain(r *Result) bool {
+	if !c.dropClosed {
+		return true
+	}
+	switch r.Status {
+	case StatusOpen, StatusFiltered, StatusOpenFiltered, StatusUp:
+		return true
+	default:
+		return false
+	}
+}
+
+// Restore seeds the collector with results and counters from an earlier,
+// interrupted run of the same scan. Restored results are streamed out
+// again since output files start afresh.
+func (c *Collector) Restore(results []*Result, summary Summary) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, r := range results {
+		c.stream(r)
+		if c.retain(r) {
+			c.results = append(c.results, r)
+		}
+	}
+
+	c.summary.TotalScanned += summary.TotalScanned
+	c.summary.OpenPorts += summary.OpenPorts
+	c.summary.ClosedPorts += summary.ClosedPorts
+	c.summary.Filtered += summary.Filtered
+	c.summary.OpenFiltered += summary.OpenFiltered
+	c.summary.Errors += summary.Errors
+	c.summary.HostsUp += summary.HostsUp
+	c.summary.HostsDown += summary.HostsDown
+}
+
+// CountHost records whether a host answered host discovery
+func (c *Collector) CountHost(up bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if up {
+		c.summary.HostsUp++
+	} else {
+		c.summary.HostsDown++
+	}
 }
 
 // updateSummary updates the summary statistics
 func (c *Collector) updateSummary(r *Result) {
+	// Live hosts are counted by CountHost rather than as scanned ports
+	if r.Status == StatusUp {
+		return
+	}
+
 	c.summary.TotalScanned++
 	switch r.Status {
 	case StatusOpen:
@@ -116,18 +415,13 @@ func (c *Collector) updateSummary(r *Result) {
 		c.summary.ClosedPorts++
 	case StatusFiltered:
 		c.summary.Filtered++
+	case StatusOpenFiltered:
+		c.summary.OpenFiltered++
 	case StatusError:
 		c.summary.Errors++
 	}
 }
 
-// printResult prints a single result (for verbose mode)
-func (c *Collector) printResult(r *Result) {
-	if r.Status == StatusOpen {
-		fmt.Fprintf(c.writer, "[+] %s:%d - %s\n", r.IP, r.Port, r.Status)
-	}
-}
-
 // Close closes the collector and waits for all results to be processed
 func (c *Collector) Close() {
 	close(c.resultChan)
@@ -136,12 +430,14 @@ func (c *Collector) Close() {
 	c.summary.Duration = c.summary.EndTime.Sub(c.summary.StartTime)
 }
 
-// WriteResults writes all collected results in the specified format
+// WriteResults finishes every output. Streamed results have already been
+// written; other outputs receive every collected result now, sorted by IP
+// and port. Output files are closed afterwards.
 func (c *Collector) WriteResults() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	defer c.closeSinks()
 
-	// Sort results by IP and port
 	sort.Slice(c.results, func(i, j int) bool {
 		if c.results[i].IP != c.results[j].IP {
 			return c.results[i].IP < c.results[j].IP
@@ -149,74 +445,24 @@ func (c *Collector) WriteResults() error {
 		return c.results[i].Port < c.results[j].Port
 	})
 
-	switch c.format {
-	case "json":
-		return c.writeJSON()
-	case "csv":
-		return c.writeCSV()
-	case "text":
-		return c.writeText()
-	default:
-		return fmt.Errorf("unsupported format: %s", c.format)
-	}
-}
-
-// writeJSON writes results in JSON format
-func (c *Collector) writeJSON() error {
-	encoder := json.NewEncoder(c.writer)
-	encoder.SetIndent("", "  ")
-
-	output := map[string]interface{}{
-		"summary": c.summary,
-		"results": c.results,
-	}
-
-	return encoder.Encode(output)
-}
-
-// writeCSV writes results in CSV format
-func (c *Collector) writeCSV() error {
-	writer := csv.NewWriter(c.writer)
-	defer writer.Flush()
-
-	// Write header
-	if err := writer.Write([]string{"IP", "Port", "Status", "Timestamp", "Duration", "Error"}); err != nil {
-		return err
-	}
-
-	// Write results
-	for _, r := range c.results {
-		record := []string{
-			r.IP,
-			fmt.Sprintf("%d", r.Port),
-			string(r.Status),
-			r.Timestamp.Format(time.RFC3339),
-			r.Duration.String(),
-			r.Error,
-		}
-		if err := writer.Write(record); err != nil {
-			return err
+	var firstErr error
+	for _, s := range c.sinks {
+		if !s.streaming {
+			for _, r := range c.results {
+				s.write(r)
+			}
 		}
-	}
-
-	return nil
-}
-
-// writeText writes results in human-readable text format
-func (c *Collector) writeText() error {
-	// If verbose mode was on, results were already printed
-	if c.verbose {
-		return nil
-	}
 
-	// Otherwise, print all results now
-	for _, r := range c.results {
-		if r.Status == StatusOpen {
-			fmt.Fprintf(c.writer, "%s:%d - %s\n", r.IP, r.Port, r.Status)
+		err := s.err
+		if err == nil {
+			err = s.writer.Flush(c.summary)
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
 		}
 	}
 
-	return nil
+	return firstErr
 }
 
 // GetSummary returns the current summary statistics
@@ -233,4 +479,4 @@ func (c *Collector) GetResults() []*Result {
 	resultsCopy := make([]*Result, len(c.results))
 	copy(resultsCopy, c.results)
 	return resultsCopy
-}
\ No newline at end of file
+}
@@ -0,0 +1,247 @@
+package result
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Writer renders results in one output format
+type Writer interface {
+	// WriteResult writes a single result
+	WriteResult(r *Result) error
+
+	// Flush completes the output once every result has been written
+	Flush(summary Summary) error
+}
+
+// streamingFormats lists the formats that can be written as results
+// arrive; the others are documents that need every result up front
+var streamingFormats = map[string]bool{
+	"text":   true,
+	"csv":    true,
+	"ndjson": true,
+}
+
+// CanStream reports whether a format can be written as results arrive
+func CanStream(format string) bool {
+	return streamingFormats[format]
+}
+
+// NewWriter creates a writer for the given format
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	switch format {
+	case "text":
+		return &textWriter{w: w}, nil
+	case "csv":
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case "ndjson":
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	case "json":
+		return &jsonWriter{w: w}, nil
+	case "xml":
+		return &xmlWriter{w: w}, nil
+	case "grep":
+		return &grepWriter{w: w}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// textWriter prints one human-readable line per open port or live host
+type textWriter struct {
+	w      io.Writer
+	prefix string
+}
+
+func (t *textWriter) WriteResult(r *Result) error {
+	if r.Status != StatusOpen && r.Status != StatusUp {
+		return nil
+	}
+	_, err := fmt.Fprintf(t.w, "%s%s\n", t.prefix, formatText(r))
+	return err
+}
+
+func (t *textWriter) Flush(summary Summary) error {
+	return nil
+}
+
+// csvWriter writes one CSV record per result, flushing each record so the
+// file is usable while the scan is still running
+type csvWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func (c *csvWriter) WriteResult(r *Result) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	record := []string{
+		r.IP,
+		r.Hostname,
+		fmt.Sprintf("%d", r.Port),
+		r.Protocol,
+		string(r.Status),
+		r.Reason,
+		r.Timestamp.Format(time.RFC3339),
+		r.Duration.String(),
+		r.Error,
+		r.Banner,
+		r.Service,
+		r.Product,
+		r.Version,
+		r.CPE,
+	}
+	record = append(record, tlsColumns(r.TLS)...)
+	record = append(record, httpColumns(r.HTTP)...)
+	if err := c.w.Write(record); err != nil {
+		return err
+	}
+
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Flush(summary Summary) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// writeHeader writes the header row before the first record
+func (c *csvWriter) writeHeader() error {
+	if c.header {
+		return nil
+	}
+	c.header = true
+
+	return c.w.Write([]string{"IP", "Hostname", "Port", "Protocol", "Status", "Reason", "Timestamp", "Duration", "Error", "Banner", "Service", "Product", "Version", "CPE",
+		"TLS Version", "TLS Cipher", "Cert Subject", "Cert Issuer", "Cert Expiry",
+		"HTTP Status", "HTTP Title", "HTTP Server", "Favicon MMH3"})
+}
+
+// ndjsonWriter writes each result as a JSON object on its own line
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonWriter) WriteResult(r *Result) error {
+	return n.enc.Encode(r)
+}
+
+func (n *ndjsonWriter) Flush(summary Summary) error {
+	return nil
+}
+
+// jsonWriter buffers results and writes a single JSON document holding the
+// summary and every result
+type jsonWriter struct {
+	w       io.Writer
+	results []*Result
+}
+
+func (j *jsonWriter) WriteResult(r *Result) error {
+	j.results = append(j.results, r)
+	return nil
+}
+
+func (j *jsonWriter) Flush(summary Summary) error {
+	encoder := json.NewEncoder(j.w)
+	encoder.SetIndent("", "  ")
+
+	report := Report{
+		Summary: summary,
+		Results: j.results,
+	}
+	if report.Results == nil {
+		report.Results = make([]*Result, 0)
+	}
+
+	return encoder.Encode(report)
+}
+
+// tlsColumns renders the CSV columns describing a result's TLS details
+func tlsColumns(t *TLSInfo) []string {
+	if t == nil {
+		return []string{"", "", "", "", ""}
+	}
+	columns := []string{t.Version, t.CipherSuite, "", "", ""}
+	if leaf := t.Leaf(); leaf != nil {
+		columns[2] = leaf.Subject
+		columns[3] = leaf.Issuer
+		columns[4] = leaf.NotAfter.Format(time.RFC3339)
+	}
+	return columns
+}
+
+// httpColumns renders the CSV columns describing a result's HTTP response
+func httpColumns(h *HTTPInfo) []string {
+	if h == nil {
+		return []string{"", "", "", ""}
+	}
+	columns := []string{strconv.Itoa(h.StatusCode), h.Title, h.Server, ""}
+	if h.FaviconMMH3 != nil {
+		columns[3] = strconv.Itoa(int(*h.FaviconMMH3))
+	}
+	return columns
+}
+
+// formatText renders a result as a single human-readable line
+func formatText(r *Result) string {
+	if r.Status == StatusUp {
+		return formatHost(r)
+	}
+
+	line := fmt.Sprintf("%s:%d - %s", r.IP, r.Port, r.Status)
+	if r.Hostname != "" {
+		line = fmt.Sprintf("%s:%d (%s) - %s", r.IP, r.Port, r.Hostname, r.Status)
+	}
+	if r.Service != "" {
+		line += " - " + r.Service
+		if product := strings.TrimSpace(r.Product + " " + r.Version); product != "" {
+			line += " (" + product + ")"
+		}
+	}
+	if r.TLS != nil {
+		line += " - " + r.TLS.Version
+		if leaf := r.TLS.Leaf(); leaf != nil {
+			line += fmt.Sprintf(" [%s, expires %s]", leaf.Subject, leaf.NotAfter.Format("2006-01-02"))
+		}
+	}
+	if r.HTTP != nil {
+		line += fmt.Sprintf(" - [%d]", r.HTTP.StatusCode)
+		if r.HTTP.Title != "" {
+			line += fmt.Sprintf(" %q", r.HTTP.Title)
+		}
+		if r.HTTP.Server != "" {
+			line += " " + r.HTTP.Server
+		}
+	}
+	if r.Banner != "" {
+		line += " - " + r.Banner
+	}
+	return line
+}
+
+// formatHost renders a live host found by host discovery, e.g.
+// "10.0.0.5 (db01) - up (tcp/22)"
+func formatHost(r *Result) string {
+	line := r.IP
+	if r.Hostname != "" {
+		line += " (" + r.Hostname + ")"
+	}
+
+	probe := r.Protocol
+	if r.Port != 0 {
+		probe += "/" + strconv.Itoa(r.Port)
+	}
+	return fmt.Sprintf("%s - up (%s)", line, probe)
+}
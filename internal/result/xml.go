@@ -0,0 +1,412 @@
+package result
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// xmlOutputVersion is the nmap XML schema version the output follows
+const xmlOutputVersion = "1.05"
+
+// xmlWriter buffers results and writes them as an nmap-compatible
+// <nmaprun> document, one <host> per scanned address
+type xmlWriter struct {
+	w       io.Writer
+	results []*Result
+}
+
+func (x *xmlWriter) WriteResult(r *Result) error {
+	x.results = append(x.results, r)
+	return nil
+}
+
+func (x *xmlWriter) Flush(summary Summary) error {
+	run := nmapRun{
+		Scanner:          "netscout",
+		Args:             strings.Join(os.Args, " "),
+		Start:            summary.StartTime.Unix(),
+		StartStr:         summary.StartTime.Format(time.ANSIC),
+		XMLOutputVersion: xmlOutputVersion,
+		ScanInfo:         scanInfo(x.results, summary.ScanType),
+		Hosts:            groupHosts(x.results),
+	}
+
+	up := 0
+	for _, h := range run.Hosts {
+		if h.Status.State == "up" {
+			up++
+		}
+	}
+	down := len(run.Hosts) - up
+
+	// Hosts that failed discovery leave no results behind, so discovery
+	// scans take their counts from the summary
+	if summary.HostsUp+summary.HostsDown > 0 {
+		up, down = summary.HostsUp, summary.HostsDown
+	}
+
+	run.RunStats = nmapRunStats{
+		Finished: nmapFinished{
+			Time:    summary.EndTime.Unix(),
+			TimeStr: summary.EndTime.Format(time.ANSIC),
+			Elapsed: fmt.Sprintf("%.2f", summary.Duration.Seconds()),
+			Summary: fmt.Sprintf("netscout done; %d IP addresses (%d hosts up) scanned in %.2f seconds",
+				up+down, up, summary.Duration.Seconds()),
+			Exit: "success",
+		},
+		Hosts: nmapHostStats{
+			Up:    up,
+			Down:  down,
+			Total: up + down,
+		},
+	}
+
+	if _, err := io.WriteString(x.w, xml.Header+"<!DOCTYPE nmaprun>\n"); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(x.w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(run); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(x.w, "\n")
+	return err
+}
+
+type nmapRun struct {
+	XMLName          xml.Name       `xml:"nmaprun"`
+	Scanner          string         `xml:"scanner,attr"`
+	Args             string         `xml:"args,attr"`
+	Start            int64          `xml:"start,attr"`
+	StartStr         string         `xml:"startstr,attr"`
+	XMLOutputVersion string         `xml:"xmloutputversion,attr"`
+	ScanInfo         []nmapScanInfo `xml:"scaninfo"`
+	Verbose          nmapLevel      `xml:"verbose"`
+	Debugging        nmapLevel      `xml:"debugging"`
+	Hosts            []nmapHost     `xml:"host"`
+	RunStats         nmapRunStats   `xml:"runstats"`
+}
+
+type nmapScanInfo struct {
+	Type        string `xml:"type,attr"`
+	Protocol    string `xml:"protocol,attr"`
+	NumServices int    `xml:"numservices,attr"`
+	Services    string `xml:"services,attr"`
+}
+
+type nmapLevel struct {
+	Level int `xml:"level,attr"`
+}
+
+type nmapHost struct {
+	StartTime int64         `xml:"starttime,attr"`
+	EndTime   int64         `xml:"endtime,attr"`
+	Status    nmapStatus    `xml:"status"`
+	Address   nmapAddress   `xml:"address"`
+	Hostnames nmapHostnames `xml:"hostnames"`
+	Ports     nmapPorts     `xml:"ports"`
+
+	// discovered marks hosts whose status came from host discovery
+	discovered bool
+}
+
+type nmapStatus struct {
+	State     string `xml:"state,attr"`
+	Reason    string `xml:"reason,attr"`
+	ReasonTTL int    `xml:"reason_ttl,attr"`
+}
+
+type nmapAddress struct {
+	Addr     string `xml:"addr,attr"`
+	AddrType string `xml:"addrtype,attr"`
+}
+
+type nmapHostnames struct {
+	Hostnames []nmapHostname `xml:"hostname"`
+}
+
+type nmapHostname struct {
+	Name string `xml:"name,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type nmapPorts struct {
+	Ports []nmapPort `xml:"port"`
+}
+
+type nmapPort struct {
+	Protocol string       `xml:"protocol,attr"`
+	PortID   int          `xml:"portid,attr"`
+	State    nmapState    `xml:"state"`
+	Service  *nmapService `xml:"service"`
+	Scripts  []nmapScript `xml:"script"`
+}
+
+type nmapState struct {
+	State     string `xml:"state,attr"`
+	Reason    string `xml:"reason,attr"`
+	ReasonTTL int    `xml:"reason_ttl,attr"`
+}
+
+type nmapService struct {
+	Name    string   `xml:"name,attr"`
+	Product string   `xml:"product,attr,omitempty"`
+	Version string   `xml:"version,attr,omitempty"`
+	Tunnel  string   `xml:"tunnel,attr,omitempty"`
+	Method  string   `xml:"method,attr"`
+	Conf    int      `xml:"conf,attr"`
+	CPE     []string `xml:"cpe,omitempty"`
+}
+
+type nmapScript struct {
+	ID     string `xml:"id,attr"`
+	Output string `xml:"output,attr"`
+}
+
+type nmapRunStats struct {
+	Finished nmapFinished  `xml:"finished"`
+	Hosts    nmapHostStats `xml:"hosts"`
+}
+
+type nmapFinished struct {
+	Time    int64  `xml:"time,attr"`
+	TimeStr string `xml:"timestr,attr"`
+	Elapsed string `xml:"elapsed,attr"`
+	Summary string `xml:"summary,attr"`
+	Exit    string `xml:"exit,attr"`
+}
+
+type nmapHostStats struct {
+	Up    int `xml:"up,attr"`
+	Down  int `xml:"down,attr"`
+	Total int `xml:"total,attr"`
+}
+
+// scanInfo describes each protocol scanned and the ports covered. TCP
+// ports are reported as connect scanned unless scanType is "syn".
+func scanInfo(results []*Result, scanType string) []nmapScanInfo {
+	ports := make(map[string]map[int]bool)
+	for _, r := range results {
+		if r.Status == StatusUp {
+			continue
+		}
+		if ports[r.Protocol] == nil {
+			ports[r.Protocol] = make(map[int]bool)
+		}
+		ports[r.Protocol][r.Port] = true
+	}
+
+	protocols := make([]string, 0, len(ports))
+	for protocol := range ports {
+		protocols = append(protocols, protocol)
+	}
+	sort.Strings(protocols)
+
+	infos := make([]nmapScanInfo, 0, len(protocols))
+	for _, protocol := range protocols {
+		kind := "connect"
+		switch {
+		case protocol == "udp":
+			kind = "udp"
+		case scanType == "syn":
+			kind = "syn"
+		}
+		infos = append(infos, nmapScanInfo{
+			Type:        kind,
+			Protocol:    protocol,
+			NumServices: len(ports[protocol]),
+			Services:    portRanges(ports[protocol]),
+		})
+	}
+	return infos
+}
+
+// portRanges renders a port set in nmap's compact form, e.g. "22,80-82"
+func portRanges(set map[int]bool) string {
+	ports := make([]int, 0, len(set))
+	for port := range set {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+
+	var parts []string
+	for i := 0; i < len(ports); {
+		j := i
+		for j+1 < len(ports) && ports[j+1] == ports[j]+1 {
+			j++
+		}
+		if i == j {
+			parts = append(parts, strconv.Itoa(ports[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", ports[i], ports[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
+
+// groupHosts gathers results into one host element per address, keeping
+// the order in which addresses first appear
+func groupHosts(results []*Result) []nmapHost {
+	var hosts []nmapHost
+	index := make(map[string]int)
+
+	for _, r := range results {
+		i, ok := index[r.IP]
+		if !ok {
+			i = len(hosts)
+			index[r.IP] = i
+			hosts = append(hosts, newHost(r))
+		}
+
+		h := &hosts[i]
+		h.StartTime = min(h.StartTime, r.Timestamp.Unix())
+		h.EndTime = max(h.EndTime, r.Timestamp.Add(r.Duration).Unix())
+		addHostname(h, r.Hostname)
+
+		// The reply to discovery is why the host counts as up, even when
+		// all its ports are filtered
+		if r.Status == StatusUp {
+			h.Status = nmapStatus{State: "up", Reason: hostReason(r)}
+			h.discovered = true
+			continue
+		}
+		h.Ports.Ports = append(h.Ports.Ports, newPort(r))
+
+		// Without discovery, any reply from the host, even a refusal,
+		// shows that it is up
+		if !h.discovered && (r.Status == StatusOpen || r.Status == StatusClosed) {
+			h.Status = nmapStatus{State: "up", Reason: portReason(r)}
+		}
+	}
+
+	return hosts
+}
+
+// newHost starts a host element for the result's address
+func newHost(r *Result) nmapHost {
+	addrType := "ipv4"
+	if addr, err := netip.ParseAddr(r.IP); err == nil && addr.Is6() && !addr.Is4In6() {
+		addrType = "ipv6"
+	}
+
+	return nmapHost{
+		StartTime: r.Timestamp.Unix(),
+		EndTime:   r.Timestamp.Unix(),
+		Status:    nmapStatus{State: "down", Reason: "no-response"},
+		Address:   nmapAddress{Addr: r.IP, AddrType: addrType},
+	}
+}
+
+// addHostname records a name the host was scanned under, once
+func addHostname(h *nmapHost, name string) {
+	if name == "" {
+		return
+	}
+	for _, existing := range h.Hostnames.Hostnames {
+		if existing.Name == name {
+			return
+		}
+	}
+	h.Hostnames.Hostnames = append(h.Hostnames.Hostnames, nmapHostname{Name: name, Type: "user"})
+}
+
+// newPort converts a result into a port element
+func newPort(r *Result) nmapPort {
+	state := string(r.Status)
+	if r.Status == StatusError {
+		// nmap has no error state; the port's state could not be determined
+		state = "unknown"
+	}
+
+	port := nmapPort{
+		Protocol: r.Protocol,
+		PortID:   r.Port,
+		State:    nmapState{State: state, Reason: portReason(r)},
+	}
+
+	if r.Service != "" {
+		port.Service = &nmapService{
+			Name:    r.Service,
+			Product: r.Product,
+			Version: r.Version,
+			Method:  "probed",
+			Conf:    10,
+		}
+		if r.CPE != "" {
+			port.Service.CPE = []string{r.CPE}
+		}
+		if r.TLS != nil {
+			port.Service.Tunnel = "ssl"
+		}
+	}
+
+	if r.Banner != "" {
+		port.Scripts = append(port.Scripts, nmapScript{ID: "banner", Output: r.Banner})
+	}
+	if leaf := r.TLS.Leaf(); leaf != nil {
+		port.Scripts = append(port.Scripts, nmapScript{
+			ID: "ssl-cert",
+			Output: fmt.Sprintf("Subject: %s\nIssuer: %s\nNot valid before: %s\nNot valid after:  %s",
+				leaf.Subject, leaf.Issuer,
+				leaf.NotBefore.UTC().Format("2006-01-02T15:04:05"),
+				leaf.NotAfter.UTC().Format("2006-01-02T15:04:05")),
+		})
+	}
+	if r.HTTP != nil && r.HTTP.Title != "" {
+		port.Scripts = append(port.Scripts, nmapScript{ID: "http-title", Output: r.HTTP.Title})
+	}
+
+	return port
+}
+
+// hostReason names the discovery probe a live host answered, using nmap's
+// terms
+func hostReason(r *Result) string {
+	if r.Reason != "" {
+		return r.Reason
+	}
+
+	switch r.Protocol {
+	case "icmp":
+		return "echo-reply"
+	case "arp":
+		return "arp-response"
+	default:
+		return "tcp-response"
+	}
+}
+
+// portReason names why a port was given its state, using nmap's terms.
+// Results saved before reasons were recorded get one from their status.
+func portReason(r *Result) string {
+	if r.Reason != "" {
+		return r.Reason
+	}
+
+	switch r.Status {
+	case StatusOpen:
+		if r.Protocol == "udp" {
+			return "udp-response"
+		}
+		return "syn-ack"
+	case StatusClosed:
+		if r.Protocol == "udp" {
+			return "port-unreach"
+		}
+		return "conn-refused"
+	case StatusError:
+		return "error"
+	default:
+		return "no-response"
+	}
+}
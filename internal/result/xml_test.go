@@ -0,0 +1,232 @@
+package result
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestXMLFlush(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	results := []*Result{
+		{
+			IP: "10.0.0.1", Hostname: "web.test", Port: 22, Protocol: "tcp", Status: StatusOpen, Reason: ReasonSynAck,
+			Timestamp: start, Service: "ssh", Product: "OpenSSH", Version: "9.6p1", CPE: "cpe:/a:openbsd:openssh:9.6p1",
+		},
+		{IP: "10.0.0.1", Hostname: "web.test", Port: 23, Protocol: "tcp", Status: StatusClosed, Reason: ReasonReset, Timestamp: start},
+		{
+			IP: "10.0.0.1", Hostname: "web.test", Port: 443, Protocol: "tcp", Status: StatusOpen, Reason: ReasonSynAck,
+			Timestamp: start.Add(time.Second), Duration: time.Second, Service: "http",
+			TLS:  &TLSInfo{Version: "TLS 1.3"},
+			HTTP: &HTTPInfo{StatusCode: 200, Title: "Welcome"},
+		},
+		{IP: "10.0.0.1", Hostname: "web.test", Port: 53, Protocol: "udp", Status: StatusOpenFiltered, Reason: ReasonNoResponse, Timestamp: start},
+		{IP: "2001:db8::5", Port: 22, Protocol: "tcp", Status: StatusFiltered, Reason: ReasonNoResponse, Timestamp: start},
+		{IP: "10.0.0.3", Protocol: "icmp", Status: StatusUp, Reason: ReasonEchoReply, Timestamp: start},
+	}
+	summary := Summary{ScanType: "syn", StartTime: start, EndTime: start.Add(3 * time.Second), Duration: 3 * time.Second}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if err := w.WriteResult(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(summary); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(buf.String(), xml.Header+"<!DOCTYPE nmaprun>\n<nmaprun ") {
+		t.Errorf("document does not start like nmap's:\n%.120s", buf.String())
+	}
+
+	var run nmapRun
+	if err := xml.Unmarshal(buf.Bytes(), &run); err != nil {
+		t.Fatal(err)
+	}
+
+	if run.Scanner != "netscout" || run.XMLOutputVersion != xmlOutputVersion || run.Start != start.Unix() {
+		t.Errorf("nmaprun = %q version %q start %d", run.Scanner, run.XMLOutputVersion, run.Start)
+	}
+
+	wantInfo := []nmapScanInfo{
+		{Type: "syn", Protocol: "tcp", NumServices: 3, Services: "22-23,443"},
+		{Type: "udp", Protocol: "udp", NumServices: 1, Services: "53"},
+	}
+	if !reflect.DeepEqual(run.ScanInfo, wantInfo) {
+		t.Errorf("scaninfo = %+v, want %+v", run.ScanInfo, wantInfo)
+	}
+
+	if len(run.Hosts) != 3 {
+		t.Fatalf("got %d hosts, want 3", len(run.Hosts))
+	}
+
+	web := run.Hosts[0]
+	if web.Status.State != "up" || web.Address != (nmapAddress{Addr: "10.0.0.1", AddrType: "ipv4"}) {
+		t.Errorf("host = %+v %+v, want 10.0.0.1 up", web.Status, web.Address)
+	}
+	if web.StartTime != start.Unix() || web.EndTime != start.Add(2*time.Second).Unix() {
+		t.Errorf("host times = %d-%d", web.StartTime, web.EndTime)
+	}
+	if want := []nmapHostname{{Name: "web.test", Type: "user"}}; !reflect.DeepEqual(web.Hostnames.Hostnames, want) {
+		t.Errorf("hostnames = %+v, want %+v", web.Hostnames.Hostnames, want)
+	}
+
+	wantPorts := []struct {
+		protocol, state, reason, service string
+	}{
+		{"tcp", "open", ReasonSynAck, "ssh"},
+		{"tcp", "closed", ReasonReset, ""},
+		{"tcp", "open", ReasonSynAck, "http"},
+		{"udp", "open|filtered", ReasonNoResponse, ""},
+	}
+	if len(web.Ports.Ports) != len(wantPorts) {
+		t.Fatalf("got %d ports, want %d", len(web.Ports.Ports), len(wantPorts))
+	}
+	for i, want := range wantPorts {
+		p := web.Ports.Ports[i]
+		service := ""
+		if p.Service != nil {
+			service = p.Service.Name
+		}
+		if p.Protocol != want.protocol || p.State.State != want.state || p.State.Reason != want.reason || service != want.service {
+			t.Errorf("port %d = %s %s (%s) %q, want %s %s (%s) %q", p.PortID, p.Protocol, p.State.State, p.State.Reason, service,
+				want.protocol, want.state, want.reason, want.service)
+		}
+	}
+
+	ssh := web.Ports.Ports[0].Service
+	wantSSH := &nmapService{Name: "ssh", Product: "OpenSSH", Version: "9.6p1", Method: "probed", Conf: 10, CPE: []string{"cpe:/a:openbsd:openssh:9.6p1"}}
+	if !reflect.DeepEqual(ssh, wantSSH) {
+		t.Errorf("ssh service = %+v, want %+v", ssh, wantSSH)
+	}
+	https := web.Ports.Ports[2]
+	if https.Service == nil || https.Service.Tunnel != "ssl" {
+		t.Errorf("service = %+v, want an ssl tunnel", https.Service)
+	}
+	if want := []nmapScript{{ID: "http-title", Output: "Welcome"}}; !reflect.DeepEqual(https.Scripts, want) {
+		t.Errorf("scripts = %+v, want %+v", https.Scripts, want)
+	}
+
+	filtered := run.Hosts[1]
+	if filtered.Status.State != "down" || filtered.Address.AddrType != "ipv6" {
+		t.Errorf("host = %+v %+v, want a down ipv6 host", filtered.Status, filtered.Address)
+	}
+
+	pinged := run.Hosts[2]
+	if pinged.Status != (nmapStatus{State: "up", Reason: ReasonEchoReply}) || len(pinged.Ports.Ports) != 0 {
+		t.Errorf("host = %+v with %d ports, want up by echo-reply without ports", pinged.Status, len(pinged.Ports.Ports))
+	}
+
+	if want := (nmapHostStats{Up: 2, Down: 1, Total: 3}); run.RunStats.Hosts != want {
+		t.Errorf("runstats hosts = %+v, want %+v", run.RunStats.Hosts, want)
+	}
+	finished := run.RunStats.Finished
+	if finished.Time != summary.EndTime.Unix() || finished.Elapsed != "3.00" || finished.Exit != "success" {
+		t.Errorf("finished = %+v", finished)
+	}
+}
+
+func TestXMLRunStatsFromDiscovery(t *testing.T) {
+	var buf bytes.Buffer
+	w := &xmlWriter{w: &buf}
+	w.WriteResult(&Result{IP: "10.0.0.1", Protocol: "icmp", Status: StatusUp})
+
+	// Hosts that failed discovery leave no results, only a count
+	if err := w.Flush(Summary{HostsUp: 1, HostsDown: 254}); err != nil {
+		t.Fatal(err)
+	}
+
+	var run nmapRun
+	if err := xml.Unmarshal(buf.Bytes(), &run); err != nil {
+		t.Fatal(err)
+	}
+	if want := (nmapHostStats{Up: 1, Down: 254, Total: 255}); run.RunStats.Hosts != want {
+		t.Errorf("runstats hosts = %+v, want %+v", run.RunStats.Hosts, want)
+	}
+	if len(run.ScanInfo) != 0 {
+		t.Errorf("scaninfo = %+v, want none for a discovery scan", run.ScanInfo)
+	}
+}
+
+func TestPortRanges(t *testing.T) {
+	tests := []struct {
+		ports []int
+		want  string
+	}{
+		{nil, ""},
+		{[]int{80}, "80"},
+		{[]int{443, 22, 80, 81, 82}, "22,80-82,443"},
+		{[]int{1, 2, 3, 5, 6}, "1-3,5-6"},
+	}
+
+	for _, tt := range tests {
+		set := make(map[int]bool)
+		for _, p := range tt.ports {
+			set[p] = true
+		}
+		if got := portRanges(set); got != tt.want {
+			t.Errorf("portRanges(%v) = %q, want %q", tt.ports, got, tt.want)
+		}
+	}
+}
+
+func TestGroupHostsStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []*Result
+		state   string
+		reason  string
+	}{
+		{
+			name:    "refused port",
+			results: []*Result{{IP: "10.0.0.1", Port: 22, Protocol: "tcp", Status: StatusClosed, Reason: ReasonConnRefused}},
+			state:   "up",
+			reason:  ReasonConnRefused,
+		},
+		{
+			name:    "no replies",
+			results: []*Result{{IP: "10.0.0.1", Port: 22, Protocol: "tcp", Status: StatusFiltered, Reason: ReasonNoResponse}},
+			state:   "down",
+			reason:  ReasonNoResponse,
+		},
+		{
+			name: "discovered with every port filtered",
+			results: []*Result{
+				{IP: "10.0.0.1", Protocol: "icmp", Status: StatusUp, Reason: ReasonEchoReply},
+				{IP: "10.0.0.1", Port: 22, Protocol: "tcp", Status: StatusFiltered, Reason: ReasonNoResponse},
+			},
+			state:  "up",
+			reason: ReasonEchoReply,
+		},
+		{
+			name: "discovery reply kept over port replies",
+			results: []*Result{
+				{IP: "10.0.0.1", Port: 22, Protocol: "tcp", Status: StatusOpen, Reason: ReasonSynAck},
+				{IP: "10.0.0.1", Port: 80, Protocol: "tcp", Status: StatusUp, Reason: ReasonReset},
+				{IP: "10.0.0.1", Port: 443, Protocol: "tcp", Status: StatusClosed, Reason: ReasonConnRefused},
+			},
+			state:  "up",
+			reason: ReasonReset,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hosts := groupHosts(tt.results)
+			if len(hosts) != 1 {
+				t.Fatalf("got %d hosts, want 1", len(hosts))
+			}
+			if s := hosts[0].Status; s.State != tt.state || s.Reason != tt.reason {
+				t.Errorf("status = %s (%s), want %s (%s)", s.State, s.Reason, tt.state, tt.reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/config"
+	"github.com/JeffreyOmoakah/netscout.git/internal/parser"
+	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+)
+
+// checkpointVersion identifies the checkpoint file layout
+const checkpointVersion = 1
+
+// Checkpoint captures enough of an in-progress scan to continue it later
+// without re-probing tasks that already completed
+type Checkpoint struct {
+	Version int            `json:"version"`
+	SavedAt time.Time      `json:"saved_at"`
+	Config  *config.Config `json:"config"`
+
+	// Total is the number of tasks in the stream, used to detect targets
+	// that expand differently on resume
+	Total uint64 `json:"total"`
+
+	// Position is the task stream index below which every task completed
+	Position uint64 `json:"position"`
+
+	// Completed lists tasks at or beyond Position that also completed
+	Completed []uint64 `json:"completed,omitempty"`
+
+	// Skipped counts tasks refused by the scope
+	Skipped uint64 `json:"skipped,omitempty"`
+
+	// Hosts lists the targets found alive by host discovery, which a
+	// resumed scan uses instead of discovering them again
+	Hosts []parser.Target `json:"hosts,omitempty"`
+
+	// HostsThrough is the number of targets discovery had settled, or
+	// math.MaxUint64 once it had finished
+	HostsThrough uint64 `json:"hosts_through,omitempty"`
+
+	Summary result.Summary   `json:"summary"`
+	Results []*result.Result `json:"results"`
+}
+
+// LoadCheckpoint reads a checkpoint written by an earlier scan
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint: %w", err)
+	}
+
+	if cp.Version != checkpointVersion {
+		return nil, fmt.Errorf("unsupported checkpoint version: %d", cp.Version)
+	}
+
+	if cp.Config == nil {
+		return nil, fmt.Errorf("checkpoint has no configuration")
+	}
+
+	return &cp, nil
+}
+
+// Save writes the checkpoint atomically, so an interruption while saving
+// never leaves a truncated file behind
+func (cp *Checkpoint) Save(path string) error {
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// taskTracker records which tasks in the stream have completed. Tasks
+// finish out of order, so it keeps a low watermark plus the set of
+// completed tasks above it; the set stays as small as the number of tasks
+// in flight.
+type taskTracker struct {
+	position  uint64
+	completed map[uint64]bool
+}
+
+// newTaskTracker creates a tracker, optionally resuming from a checkpoint
+func newTaskTracker(position uint64, completed []uint64) *taskTracker {
+	t := &taskTracker{
+		position:  position,
+		completed: make(map[uint64]bool, len(completed)),
+	}
+	for _, seq := range completed {
+		t.markDone(seq)
+	}
+	return t
+}
+
+// markDone records a completed task and advances the watermark
+func (t *taskTracker) markDone(seq uint64) {
+	if seq < t.position {
+		return
+	}
+	t.completed[seq] = true
+	for t.completed[t.position] {
+		delete(t.completed, t.position)
+		t.position++
+	}
+}
+
+// isDone reports whether a task has already completed
+func (t *taskTracker) isDone(seq uint64) bool {
+	return seq < t.position || t.completed[seq]
+}
+
+// snapshot returns the watermark and the sorted completed tasks above it
+func (t *taskTracker) snapshot() (uint64, []uint64) {
+	completed := make([]uint64, 0, len(t.completed))
+	for seq := range t.completed {
+		completed = append(completed, seq)
+	}
+	sort.Slice(completed, func(i, j int) bool {
+		return completed[i] < completed[j]
+	})
+	return t.position, completed
+}
@@ -0,0 +1,174 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/config"
+)
+
+func TestTaskTracker(t *testing.T) {
+	tests := []struct {
+		name          string
+		position      uint64
+		resumed       []uint64
+		done          []uint64
+		wantPosition  uint64
+		wantCompleted []uint64
+	}{
+		{"in order", 0, nil, []uint64{0, 1, 2}, 3, []uint64{}},
+		{"gap holds the watermark", 0, nil, []uint64{0, 2, 3}, 1, []uint64{2, 3}},
+		{"gap filled", 0, nil, []uint64{3, 1, 2, 0}, 4, []uint64{}},
+		{"below the watermark", 5, nil, []uint64{2, 5}, 6, []uint64{}},
+		{"repeated", 0, nil, []uint64{1, 1, 0}, 2, []uint64{}},
+		{"resumed", 10, []uint64{12, 14}, []uint64{10}, 11, []uint64{12, 14}},
+		{"resumed gap filled", 10, []uint64{11, 12}, []uint64{10}, 13, []uint64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := newTaskTracker(tt.position, tt.resumed)
+			for _, seq := range tt.done {
+				tr.markDone(seq)
+			}
+
+			position, completed := tr.snapshot()
+			if position != tt.wantPosition {
+				t.Errorf("position = %d, want %d", position, tt.wantPosition)
+			}
+			if !slices.Equal(completed, tt.wantCompleted) {
+				t.Errorf("completed = %v, want %v", completed, tt.wantCompleted)
+			}
+
+			for _, seq := range tt.done {
+				if !tr.isDone(seq) {
+					t.Errorf("isDone(%d) = false after markDone", seq)
+				}
+			}
+			if tr.isDone(position) {
+				t.Errorf("isDone(%d) = true at the watermark", position)
+			}
+		})
+	}
+}
+
+func TestCheckpointSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.checkpoint")
+
+	cfg := &config.Config{Targets: []string{"10.0.0.0/24"}, Ports: "80,443"}
+	cp := &Checkpoint{
+		Version:   checkpointVersion,
+		Config:    cfg,
+		Total:     254,
+		Position:  10,
+		Completed: []uint64{12, 20},
+	}
+	if err := cp.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// Saving replaces the file without leaving temporary files behind
+	if err := cp.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory holds %d files, want 1", len(entries))
+	}
+
+	got, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Total != cp.Total || got.Position != cp.Position || !slices.Equal(got.Completed, cp.Completed) {
+		t.Errorf("loaded %+v, want %+v", got, cp)
+	}
+	if !slices.Equal(got.Config.Targets, cfg.Targets) {
+		t.Errorf("targets = %v, want %v", got.Config.Targets, cfg.Targets)
+	}
+}
+
+func TestLoadCheckpoint(t *testing.T) {
+	tests := []struct {
+		name             string
+		data             string
+		wantErr          bool
+		wantHostsThrough uint64
+	}{
+		{"current", `{"version": 1, "config": {}, "hosts_through": 7}`, false, 7},
+		{"unknown version", `{"version": 2, "config": {}}`, true, 0},
+		{"no configuration", `{"version": 1}`, true, 0},
+		{"malformed", `{"version": `, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "scan.checkpoint")
+			if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			cp, err := LoadCheckpoint(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadCheckpoint error = %v, want error = %v", err, tt.wantErr)
+			}
+			if err == nil && cp.HostsThrough != tt.wantHostsThrough {
+				t.Errorf("HostsThrough = %d, want %d", cp.HostsThrough, tt.wantHostsThrough)
+			}
+		})
+	}
+}
+
+// TestScanInterrupted cancels a scan after every task has been submitted
+// but while its probes are still waiting for replies
+func TestScanInterrupted(t *testing.T) {
+	silent, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer silent.Close()
+
+	dir := t.TempDir()
+	cfg := &config.Config{
+		Targets:            []string{"127.0.0.1"},
+		Ports:              strconv.Itoa(silent.LocalAddr().(*net.UDPAddr).Port),
+		ScanType:           "udp",
+		SkipDiscovery:      true,
+		Workers:            1,
+		Timeout:            5 * time.Second,
+		OutputFile:         filepath.Join(dir, "scan.json"),
+		OutputFormat:       "json",
+		CheckpointFile:     filepath.Join(dir, "scan.checkpoint"),
+		CheckpointInterval: time.Minute,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s, err := New(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.AfterFunc(200*time.Millisecond, cancel)
+	if err := s.Scan(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Scan = %v, want %v", err, context.Canceled)
+	}
+
+	cp, err := LoadCheckpoint(cfg.CheckpointFile)
+	if err != nil {
+		t.Fatalf("checkpoint not kept: %v", err)
+	}
+	if cp.Position != 0 || len(cp.Completed) != 0 {
+		t.Errorf("checkpoint at %d with %v completed, want the unfinished probe still pending", cp.Position, cp.Completed)
+	}
+}
@@ -3,12 +3,23 @@ package scanner
 import (
 	"context"
 	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/netip"
+	"os"
 	"sync"
 	"time"
 
 	"github.com/JeffreyOmoakah/netscout.git/internal/config"
+	"github.com/JeffreyOmoakah/netscout.git/internal/discovery"
+	"github.com/JeffreyOmoakah/netscout.git/internal/fingerprint"
+	"github.com/JeffreyOmoakah/netscout.git/internal/httpprobe"
 	"github.com/JeffreyOmoakah/netscout.git/internal/parser"
 	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+	"github.com/JeffreyOmoakah/netscout.git/internal/scope"
+	"github.com/JeffreyOmoakah/netscout.git/internal/synscan"
+	"github.com/JeffreyOmoakah/netscout.git/internal/tlsprobe"
 	"github.com/JeffreyOmoakah/netscout.git/internal/worker"
 )
 
@@ -16,95 +27,414 @@ import (
 type Scanner struct {
 	config      *config.Config
 	collector   *result.Collector
-	pool        *worker.Pool
-	targets     []string
+	runner      taskRunner
+	targets     *parser.TargetSet
+	targetOpts  parser.TargetOptions
+	scope       *scope.Scope
 	ports       []int
 	resultChan  chan *result.Result
 	rateLimiter *time.Ticker
+
+	// stream is set while targets are read from a list as the scan runs
+	stream bool
+
+	// discoverer finds live hosts before the port scan (nil = -Pn)
+	discoverer *discovery.Discoverer
+
+	// rtt adapts TCP probe timeouts to each host (nil for UDP scans)
+	rtt *worker.RTTEstimator
+
+	// hosts holds the targets found alive by discovery, and hostsThrough
+	// the number of targets it has settled. While discovery runs alongside
+	// the port scan, hosts are kept only for checkpoints. discovered is set
+	// once hosts holds every live host and has become the scan's targets.
+	hosts        []parser.Target
+	hostsThrough uint64
+	discovered   bool
+
+	// mu keeps the tracker and collector consistent for checkpoints
+	mu      sync.Mutex
+	tracker *taskTracker
+
+	// skipped counts tasks refused by the scope
+	skipped uint64
 }
 
-// New creates a new Scanner instance
-func New(cfg *config.Config) (*Scanner, error) {
-	// Parse targets
-	targets, err := parser.ParseTargets(cfg.Targets)
+// New creates a new Scanner instance. ctx bounds the hostname lookups made
+// while parsing the targets.
+func New(ctx context.Context, cfg *config.Config) (*Scanner, error) {
+	// Pick a seed when none was given so the scan can still be reproduced
+	if cfg.Seed == 0 {
+		cfg.Seed = rand.Uint64()
+	}
+
+	// Load the networks the scan may touch
+	sc, err := scope.New(scope.Options{
+		File:          cfg.ScopeFile,
+		Exclude:       cfg.Exclude,
+		ExcludeFile:   cfg.ExcludeFile,
+		AllowReserved: cfg.AllowReserved,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scope: %w", err)
+	}
+
+	// Parse targets, resolving hostnames
+	resolver, err := parser.NewResolver(cfg.DNSServer, cfg.IPVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	targetOpts := parser.TargetOptions{
+		Resolver:   resolver,
+		SampleSize: cfg.SampleSize,
+		Seed:       cfg.Seed,
+		Scope:      sc,
+	}
+
+	targets, err := loadTargets(ctx, cfg, targetOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse targets: %w", err)
 	}
 
 	// Parse ports
-	ports, err := parser.ParsePorts(cfg.Ports)
+	ports, err := parser.ParsePorts(cfg.Ports, cfg.Protocol())
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse ports: %w", err)
 	}
 
-	// Create result collector
-	collector, err := result.NewCollector(cfg.OutputFile, cfg.OutputFormat, cfg.Verbose)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create collector: %w", err)
+	// Prepare host discovery unless every target is to be treated as alive
+	var discoverer *discovery.Discoverer
+	if !cfg.SkipDiscovery {
+		spec := cfg.DiscoveryPorts
+		if spec == "" {
+			spec = discovery.DefaultPorts
+		}
+		pingPorts, err := parser.ParsePorts(spec, "tcp")
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse discovery ports: %w", err)
+		}
+		discoverer = discovery.New(discovery.Options{Ports: pingPorts, Timeout: cfg.Timeout})
 	}
 
 	// Create result channel
 	resultChan := make(chan *result.Result, 1000)
 
-	// Create worker pool
-	pool := worker.NewPool(cfg.Workers, resultChan, cfg.Timeout)
+	// Adapt TCP timeouts to each host's round-trip time. UDP services can
+	// take longer to answer than the network, so UDP keeps the fixed timeout.
+	var rtt *worker.RTTEstimator
+	if cfg.Protocol() == "tcp" {
+		rtt = worker.NewRTTEstimator(cfg.Timeout, cfg.MinTimeout, cfg.MaxTimeout)
+	}
 
 	// Create rate limiter if needed
 	var rateLimiter *time.Ticker
+	var limit <-chan time.Time
 	if cfg.RateLimit > 0 {
 		interval := time.Second / time.Duration(cfg.RateLimit)
 		rateLimiter = time.NewTicker(interval)
+		limit = rateLimiter.C
+	}
+
+	// Create the worker pool or raw socket engine that runs the tasks
+	runner, err := newRunner(cfg, resultChan, rtt, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create result collector
+	collector, err := result.NewCollector(result.Options{
+		OutputFile: cfg.OutputFile,
+		Format:     cfg.OutputFormat,
+		OutputBase: cfg.OutputBase,
+		Verbose:    cfg.Verbose,
+		Stream:     cfg.StreamOutput,
+		DropClosed: cfg.DropClosed,
+		ScanType:   cfg.ScanType,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create collector: %w", err)
+	}
+
+	if cfg.TLSExpiringWithin > 0 {
+		collector.SetFilter(result.ExpiringWithin(cfg.TLSExpiringWithin))
 	}
 
 	s := &Scanner{
 		config:      cfg,
 		collector:   collector,
-		pool:        pool,
+		runner:      runner,
 		targets:     targets,
+		targetOpts:  targetOpts,
+		scope:       sc,
 		ports:       ports,
 		resultChan:  resultChan,
 		rateLimiter: rateLimiter,
+		stream:      cfg.InputList != "" && !cfg.Randomize,
+		discoverer:  discoverer,
+		rtt:         rtt,
+		tracker:     newTaskTracker(0, nil),
 	}
 
 	return s, nil
 }
 
+// loadTargets expands the targets given with -t. A target list is read in
+// full only for randomized scans, which need every target before the first
+// probe is sent; otherwise it is streamed as the scan runs.
+func loadTargets(ctx context.Context, cfg *config.Config, opts parser.TargetOptions) (*parser.TargetSet, error) {
+	specs := cfg.Targets
+	if cfg.InputList != "" {
+		if !cfg.Randomize {
+			if len(specs) == 0 {
+				return &parser.TargetSet{}, nil
+			}
+			return parser.ParseTargets(ctx, specs, opts)
+		}
+
+		list, err := readTargetList(cfg.InputList)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(append([]string(nil), specs...), list...)
+	}
+
+	return parser.ParseTargets(ctx, specs, opts)
+}
+
+// openTargetList opens a target list file, or stdin for "-"
+func openTargetList(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open target list: %w", err)
+	}
+	return f, nil
+}
+
+// listName describes a target list in messages
+func listName(path string) string {
+	if path == "-" {
+		return "stdin"
+	}
+	return path
+}
+
+// readTargetList reads every specification from a target list
+func readTargetList(path string) ([]string, error) {
+	r, err := openTargetList(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	specs, err := parser.ReadSpecs(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read target list: %w", err)
+	}
+	return specs, nil
+}
+
+// taskRunner executes scan tasks, sending each result to the scanner's
+// result channel. It is satisfied by worker.Pool and synscan.Engine.
+type taskRunner interface {
+	Start(ctx context.Context)
+	Submit(ctx context.Context, task worker.Task) error
+	Close()
+}
+
+// newRunner builds what executes the scan's tasks: the raw socket engine
+// for SYN scans, or a worker pool running the configured prober. Retries
+// wait for the rate limiter's ticks like fresh tasks.
+func newRunner(cfg *config.Config, resultChan chan<- *result.Result, rtt *worker.RTTEstimator, limit <-chan time.Time) (taskRunner, error) {
+	retry := worker.RetryPolicy{Retries: cfg.Retries, Backoff: cfg.RetryBackoff, Limiter: limit}
+
+	if cfg.ScanType == "syn" {
+		engine, err := synscan.New(synscan.Options{
+			Timeout:     cfg.Timeout,
+			MaxInFlight: cfg.Workers,
+			Retry:       retry,
+			RTT:         rtt,
+		}, resultChan)
+		if err != nil {
+			return nil, err
+		}
+		return engine, nil
+	}
+
+	prober, err := newProber(cfg, rtt)
+	if err != nil {
+		return nil, err
+	}
+	pool := worker.NewPool(cfg.Workers, resultChan, prober)
+	pool.Retry = retry
+	return pool, nil
+}
+
+// newProber builds the prober matching the configured scan type and wraps
+// it with the enrichers enabled in the configuration
+func newProber(cfg *config.Config, rtt *worker.RTTEstimator) (worker.Prober, error) {
+	prober, err := newBaseProber(cfg, rtt)
+	if err != nil {
+		return nil, err
+	}
+
+	var enrichers []worker.Enricher
+
+	if cfg.ServiceDetection {
+		db, err := loadProbeDatabase(cfg.ProbeDatabase)
+		if err != nil {
+			return nil, err
+		}
+		enrichers = append(enrichers, fingerprint.NewEngine(db, cfg.Timeout, cfg.VersionIntensity))
+	}
+
+	if cfg.TLSInspect {
+		enrichers = append(enrichers, tlsprobe.NewInspector(cfg.Timeout, cfg.TLSAllPorts, cfg.TLSVersions))
+	}
+
+	if cfg.HTTPProbe {
+		enrichers = append(enrichers, httpprobe.NewProber(cfg.Timeout))
+	}
+
+	if len(enrichers) == 0 {
+		return prober, nil
+	}
+
+	return worker.NewEnrichingProber(prober, enrichers...), nil
+}
+
+// loadProbeDatabase loads a user-supplied probe database, or the embedded
+// default when no path is given
+func loadProbeDatabase(path string) (*fingerprint.Database, error) {
+	if path == "" {
+		return fingerprint.Default()
+	}
+
+	db, err := fingerprint.LoadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load probe database: %w", err)
+	}
+
+	return db, nil
+}
+
+// newBaseProber builds the prober for the configured scan type
+func newBaseProber(cfg *config.Config, rtt *worker.RTTEstimator) (worker.Prober, error) {
+	switch cfg.ScanType {
+	case "tcp":
+		prober := worker.NewTCPProber(cfg.Timeout)
+		prober.RTT = rtt
+		if cfg.BannerGrab {
+			prober.Banner = &worker.BannerOptions{
+				ReadTimeout: cfg.BannerTimeout,
+				Probe:       []byte(cfg.BannerProbe),
+				MaxBytes:    cfg.BannerSize,
+			}
+		}
+		return prober, nil
+	case "udp":
+		return worker.NewUDPProber(cfg.Timeout), nil
+	default:
+		return nil, fmt.Errorf("unsupported scan type: %s", cfg.ScanType)
+	}
+}
+
 // Scan executes the network scan
 func (s *Scanner) Scan(ctx context.Context) error {
-	// Start the worker pool
-	s.pool.Start(ctx)
+	// Discovery-only scans report the live hosts and randomized scans
+	// shuffle them, so both let discovery finish first. A scan interrupted
+	// here, or one that only discovers hosts, ends after it. Other scans
+	// probe each host's ports as soon as discovery finds it alive.
+	if s.discoverer != nil && !s.discovered && (s.config.DiscoveryOnly || s.config.Randomize) {
+		err := s.discover(ctx)
+		if err != nil || s.config.DiscoveryOnly {
+			s.collector.Close()
+			if err := s.collector.WriteResults(); err != nil {
+				return fmt.Errorf("failed to write results: %w", err)
+			}
+			return err
+		}
+	}
+
+	// Start the worker pool or SYN engine
+	s.runner.Start(ctx)
 
 	// Start result collection goroutine
-	go s.collectResults()
+	collectDone := make(chan struct{})
+	go func() {
+		defer close(collectDone)
+		s.collectResults()
+	}()
+
+	// Start periodic checkpointing if configured
+	var checkpointWg sync.WaitGroup
+	stopCheckpoints := make(chan struct{})
+	if s.config.CheckpointFile != "" {
+		checkpointWg.Add(1)
+		go s.checkpointLoop(stopCheckpoints, &checkpointWg)
+	}
 
 	// Start progress reporting if verbose
 	var progressWg sync.WaitGroup
+	scanDone := make(chan struct{})
 	if s.config.Verbose {
 		progressWg.Add(1)
-		go s.reportProgress(ctx, &progressWg)
+		go s.reportProgress(ctx, scanDone, &progressWg)
 	}
 
 	// Generate and submit tasks
-	totalTasks := len(s.targets) * len(s.ports)
 	if s.config.Verbose {
-		fmt.Printf("Scanning %d hosts across %d ports (%d total probes)\n\n",
-			len(s.targets), len(s.ports), totalTasks)
+		switch {
+		case s.discovering():
+			fmt.Printf("Scanning live hosts across %d ports as discovery finds them\n\n", len(s.ports))
+		case s.stream:
+			fmt.Printf("Scanning hosts from %s across %d ports\n\n", listName(s.config.InputList), len(s.ports))
+		default:
+			fmt.Printf("Scanning %d hosts across %d ports (%d total probes)\n\n",
+				s.targets.Len(), len(s.ports), s.totalTasks())
+		}
 	}
 
 	err := s.generateTasks(ctx)
-	
-	// Close the worker pool task channel
-	s.pool.Close()
+
+	// Stop accepting tasks and wait for in-flight probes. An interrupt
+	// that lands after the last task was submitted still cuts those
+	// probes short, so the scan is not complete.
+	s.runner.Close()
+	if err == nil {
+		err = ctx.Err()
+	}
 
 	// Wait for progress reporter to finish
+	close(scanDone)
 	if s.config.Verbose {
 		progressWg.Wait()
 	}
 
 	// Close the result channel and wait for collection to complete
 	close(s.resultChan)
+	<-collectDone
 	s.collector.Close()
 
+	// Record where an interrupted scan stopped, or clean up after a full one
+	close(stopCheckpoints)
+	checkpointWg.Wait()
+	if s.config.CheckpointFile != "" {
+		if err != nil {
+			if cpErr := s.saveCheckpoint(); cpErr != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", cpErr)
+			} else {
+				fmt.Fprintf(os.Stderr, "Checkpoint saved to %s\n", s.config.CheckpointFile)
+			}
+		} else {
+			os.Remove(s.config.CheckpointFile)
+		}
+	}
+
 	// Write final results
 	if err := s.collector.WriteResults(); err != nil {
 		return fmt.Errorf("failed to write results: %w", err)
@@ -113,77 +443,599 @@ func (s *Scanner) Scan(ctx context.Context) error {
 	return err
 }
 
-// generateTasks creates and submits scanning tasks to the worker pool
-func (s *Scanner) generateTasks(ctx context.Context) error {
-	for _, target := range s.targets {
-		for _, port := range s.ports {
-			// Check if context is cancelled
+// hostOutcome is what host discovery found for one target
+type hostOutcome struct {
+	// index is the target's position among the scan's targets
+	index   uint64
+	target  parser.Target
+	started time.Time
+	reply   discovery.Reply
+	live    bool
+
+	// refused marks targets outside the scope, which are not pinged
+	refused bool
+}
+
+// discover pings every target and keeps those that answer as the targets
+// of the port scan, in their original order. Each live host is recorded as
+// a result too, so outputs can tell which probe it answered.
+func (s *Scanner) discover(ctx context.Context) error {
+	var hosts []parser.Target
+	up, down := 0, 0
+
+	err := s.discoverHosts(ctx, 0, func(h hostOutcome) error {
+		if h.refused {
+			return nil
+		}
+		s.collector.CountHost(h.live)
+		if !h.live {
+			down++
+			return nil
+		}
+
+		up++
+		s.collector.Add(upResult(h))
+		if !s.config.DiscoveryOnly {
+			hosts = append(hosts, h.target)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !s.config.DiscoveryOnly {
+		s.useHosts(hosts)
+	}
+
+	if s.config.Verbose {
+		fmt.Printf("Host discovery: %d up, %d down\n", up, down)
+	}
+	return nil
+}
+
+// upResult records a host found alive by discovery
+func upResult(h hostOutcome) *result.Result {
+	return &result.Result{
+		IP:        h.target.IP,
+		Hostname:  h.target.Hostname,
+		Port:      h.reply.Port,
+		Protocol:  h.reply.Method,
+		Status:    result.StatusUp,
+		Reason:    h.reply.Reason,
+		Timestamp: h.started,
+		Duration:  h.reply.RTT,
+	}
+}
+
+// discoverHosts pings the scan's targets from index from onwards and
+// passes each outcome to fn in target order, stopping at the first error
+// fn returns. Pings run concurrently, but only a window of targets is in
+// flight at once, so a slow host holds back a bounded number of outcomes
+// and the targets are never all held in memory.
+func (s *Scanner) discoverHosts(ctx context.Context, from uint64, fn func(hostOutcome) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	window := 4 * s.config.Workers
+	slots := make(chan struct{}, window)
+	jobs := make(chan hostOutcome)
+	done := make(chan hostOutcome, window)
+
+	var wg sync.WaitGroup
+	for range s.config.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for h := range jobs {
+				h.started = time.Now()
+				h.reply, h.live = s.ping(ctx, h.target)
+				done <- h
+			}
+		}()
+	}
+
+	feedErr := make(chan error, 1)
+	go func() {
+		feedErr <- s.feedHosts(ctx, from, func(h hostOutcome) error {
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
-			default:
+			case slots <- struct{}{}:
+			}
+
+			if h.refused {
+				done <- h
+				return nil
 			}
 
-			// Apply rate limiting if configured
 			if s.rateLimiter != nil {
 				select {
 				case <-ctx.Done():
 					return ctx.Err()
 				case <-s.rateLimiter.C:
-					// Continue after rate limit tick
 				}
 			}
 
-			// Submit task to worker pool
-			task := worker.Task{
-				IP:   target,
-				Port: port,
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case jobs <- h:
+				return nil
+			}
+		})
+		close(jobs)
+		wg.Wait()
+		close(done)
+	}()
+
+	// Hand outcomes to fn in target order. Once the scan is cancelled or fn
+	// fails, the rest are drained unseen, as pings cut short say nothing.
+	var err error
+	pending := make(map[uint64]hostOutcome)
+	next := from
+	for h := range done {
+		pending[h.index] = h
+		for {
+			h, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			<-slots
+
+			if err == nil {
+				err = ctx.Err()
+			}
+			if err == nil {
+				if err = fn(h); err != nil {
+					cancel()
+				}
+			}
+		}
+	}
+
+	if ferr := <-feedErr; err == nil {
+		err = ferr
+	}
+	return err
+}
+
+// feedHosts passes the scan's targets from index from onwards to fn,
+// reading a target list as it goes
+func (s *Scanner) feedHosts(ctx context.Context, from uint64, fn func(hostOutcome) error) error {
+	index := min(from, s.targets.Len())
+	feed := func(targets targetSource) error {
+		for {
+			target, ok := targets.Next()
+			if !ok {
+				return nil
+			}
+
+			// A resumed scan passes over the targets discovery has settled
+			if index < from {
+				index++
+				continue
 			}
 
-			if err := s.pool.Submit(ctx, task); err != nil {
+			if err := fn(hostOutcome{index: index, target: target, refused: !s.inScope(target)}); err != nil {
 				return err
 			}
+			index++
+		}
+	}
+
+	err := feed(s.targets.IteratorAt(index))
+	if err == nil && s.stream {
+		err = s.feedStream(ctx, feed)
+	}
+	return err
+}
+
+// ping probes one target for signs of life
+func (s *Scanner) ping(ctx context.Context, target parser.Target) (discovery.Reply, bool) {
+	addr, err := netip.ParseAddr(target.IP)
+	if err != nil {
+		return discovery.Reply{}, false
+	}
+
+	reply, ok := s.discoverer.Probe(ctx, addr)
+
+	// Seed the host's timeout with the ping's round trip. ARP answers only
+	// after the pings time out, so they say nothing.
+	if ok && s.rtt != nil && reply.Method != "arp" {
+		s.rtt.Observe(target.IP, reply.RTT)
+	}
+	return reply, ok
+}
+
+// feedStream passes the targets of a target list to fn
+func (s *Scanner) feedStream(ctx context.Context, fn func(targets targetSource) error) error {
+	r, stream, err := s.openStream(ctx)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if err := fn(stream); err != nil {
+		return err
+	}
+
+	if err := stream.Err(); err != nil {
+		return fmt.Errorf("failed to read target list: %w", err)
+	}
+	return nil
+}
+
+// discovering reports whether host discovery runs alongside the port scan
+func (s *Scanner) discovering() bool {
+	return s.discoverer != nil && !s.discovered
+}
+
+// useHosts makes the hosts found alive by discovery the scan's targets
+func (s *Scanner) useHosts(hosts []parser.Target) {
+	s.hosts = hosts
+	s.targets = parser.NewTargetSet(hosts)
+	s.stream = false
+	s.discovered = true
+}
+
+// generateTasks creates and submits scanning tasks to the worker pool.
+// Each task's sequence number is its position in the task stream; tasks
+// recorded as complete by a restored checkpoint are skipped.
+func (s *Scanner) generateTasks(ctx context.Context) error {
+	s.mu.Lock()
+	resumeFrom, completed := s.tracker.snapshot()
+	s.mu.Unlock()
+
+	skip := make(map[uint64]bool, len(completed))
+	for _, seq := range completed {
+		skip[seq] = true
+	}
+
+	if s.config.Randomize {
+		return s.generateRandomTasks(ctx, resumeFrom, skip)
+	}
+
+	if s.discovering() {
+		return s.generateDiscoveredTasks(ctx, resumeFrom, skip)
+	}
+
+	numPorts := uint64(len(s.ports))
+	start := resumeFrom / numPorts
+	if s.stream {
+		// Targets beyond the -t set are skipped while reading the list
+		start = min(start, s.targets.Len())
+	}
+	seq := start * numPorts
+	if err := s.submitTargets(ctx, s.targets.IteratorAt(start), &seq, resumeFrom, skip); err != nil {
+		return err
+	}
+
+	if !s.stream {
+		return nil
+	}
+
+	return s.generateStreamTasks(ctx, &seq, resumeFrom, skip)
+}
+
+// generateDiscoveredTasks submits tasks for each host as soon as discovery
+// finds it alive, so the port scan starts without waiting for discovery to
+// end and live hosts are held in memory only for checkpoints. Hosts found
+// before a restored checkpoint was saved come first.
+func (s *Scanner) generateDiscoveredTasks(ctx context.Context, resumeFrom uint64, skip map[uint64]bool) error {
+	s.mu.Lock()
+	known, from := s.hosts, s.hostsThrough
+	s.mu.Unlock()
+
+	var seq uint64
+	if err := s.submitTargets(ctx, parser.NewTargetSet(known).Iterator(), &seq, resumeFrom, skip); err != nil {
+		return err
+	}
+
+	keep := s.config.CheckpointFile != ""
+	return s.discoverHosts(ctx, from, func(h hostOutcome) error {
+		s.mu.Lock()
+		if !h.refused {
+			s.collector.CountHost(h.live)
+		}
+		if h.live && keep {
+			s.hosts = append(s.hosts, h.target)
+		}
+		s.hostsThrough = h.index + 1
+		s.mu.Unlock()
+
+		if !h.live {
+			return nil
+		}
+		s.collector.Add(upResult(h))
+		return s.submitTargets(ctx, parser.NewTargetSet([]parser.Target{h.target}).Iterator(), &seq, resumeFrom, skip)
+	})
+}
+
+// generateStreamTasks submits tasks for the targets in a target list,
+// reading and expanding each entry only when the scan reaches it. Entries
+// that cannot be parsed or resolved are reported and skipped.
+func (s *Scanner) generateStreamTasks(ctx context.Context, seq *uint64, resumeFrom uint64, skip map[uint64]bool) error {
+	r, stream, err := s.openStream(ctx)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	// A resumed scan passes over the targets it has already finished
+	numPorts := uint64(len(s.ports))
+	for *seq+numPorts <= resumeFrom {
+		if _, ok := stream.Next(); !ok {
+			break
+		}
+		*seq += numPorts
+	}
+
+	if err := s.submitTargets(ctx, stream, seq, resumeFrom, skip); err != nil {
+		return err
+	}
+
+	if err := stream.Err(); err != nil {
+		return fmt.Errorf("failed to read target list: %w", err)
+	}
+	return nil
+}
+
+// openStream opens the target list for reading as the scan runs. Entries
+// that cannot be parsed or resolved are reported and skipped.
+func (s *Scanner) openStream(ctx context.Context) (io.Closer, *parser.TargetStream, error) {
+	r, err := openTargetList(s.config.InputList)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stream := parser.NewTargetStream(ctx, parser.NewSpecReader(r), s.targetOpts)
+	stream.OnError = func(line int, spec string, err error) {
+		fmt.Fprintf(os.Stderr, "Warning: %s:%d: skipping %s: %v\n", listName(s.config.InputList), line, spec, err)
+	}
+	return r, stream, nil
+}
+
+// targetSource yields targets one at a time, from a TargetSet or a list
+type targetSource interface {
+	Next() (parser.Target, bool)
+}
+
+// submitTargets submits every port of each target an iterator yields,
+// numbering tasks from *seq onwards
+func (s *Scanner) submitTargets(ctx context.Context, targets targetSource, seq *uint64, resumeFrom uint64, skip map[uint64]bool) error {
+	for {
+		target, ok := targets.Next()
+		if !ok {
+			return nil
+		}
+
+		for _, port := range s.ports {
+			if *seq >= resumeFrom && !skip[*seq] {
+				if err := s.submit(ctx, target, port, *seq); err != nil {
+					return err
+				}
+			}
+			*seq++
+		}
+	}
+}
+
+// generateRandomTasks submits every host×port pair in an order given by a
+// seeded permutation of the task index space, so consecutive probes are
+// spread across hosts and the same seed reproduces the same order
+func (s *Scanner) generateRandomTasks(ctx context.Context, resumeFrom uint64, skip map[uint64]bool) error {
+	total := s.totalTasks()
+	numPorts := uint64(len(s.ports))
+	perm := parser.NewPermutation(total, s.config.Seed)
+
+	for seq := resumeFrom; seq < total; seq++ {
+		if skip[seq] {
+			continue
+		}
+
+		idx := perm.At(seq)
+		target := s.targets.At(idx / numPorts)
+		port := s.ports[idx%numPorts]
+
+		if err := s.submit(ctx, target, port, seq); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
-// collectResults receives results from workers and submits them to the collector
+// submit applies rate limiting and hands a single task to the worker pool
+func (s *Scanner) submit(ctx context.Context, target parser.Target, port int, seq uint64) error {
+	// Check if context is cancelled
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	// Never probe an address outside the scope, such as an excluded host
+	// inside a range that was otherwise allowed
+	if !s.inScope(target) {
+		s.mu.Lock()
+		s.tracker.markDone(seq)
+		s.skipped++
+		s.mu.Unlock()
+		return nil
+	}
+
+	// Apply rate limiting if configured
+	if s.rateLimiter != nil {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.rateLimiter.C:
+			// Continue after rate limit tick
+		}
+	}
+
+	// Submit task to worker pool
+	task := worker.Task{
+		IP:       target.IP,
+		Hostname: target.Hostname,
+		Port:     port,
+		Seq:      seq,
+	}
+
+	return s.runner.Submit(ctx, task)
+}
+
+// inScope reports whether the scope permits probing a target
+func (s *Scanner) inScope(target parser.Target) bool {
+	addr, err := netip.ParseAddr(target.IP)
+	return err == nil && s.scope.Allows(addr, target.Hostname)
+}
+
+// totalTasks returns the number of probes in the scan, saturating at
+// math.MaxUint64 for enormous IPv6 ranges. It is 0 when targets are
+// streamed from a list or hosts are scanned as discovery finds them, as
+// the total is not known until the list or discovery ends.
+func (s *Scanner) totalTasks() uint64 {
+	if s.stream || s.discovering() {
+		return 0
+	}
+	return parser.SatMul(s.targets.Len(), uint64(len(s.ports)))
+}
+
+// collectResults receives results from workers, hands them to the
+// collector and marks their tasks complete
 func (s *Scanner) collectResults() {
 	for r := range s.resultChan {
-		s.collector.Submit(r)
+		s.mu.Lock()
+		s.collector.Add(r)
+		s.tracker.markDone(r.Seq)
+		s.mu.Unlock()
 	}
 }
 
-// reportProgress periodically reports scan progress
-func (s *Scanner) reportProgress(ctx context.Context, wg *sync.WaitGroup) {
+// Restore continues an interrupted scan from a checkpoint: completed tasks
+// are skipped and their results carried over. The scanner must have been
+// created from the checkpoint's configuration.
+func (s *Scanner) Restore(cp *Checkpoint) error {
+	// Hosts already found are scanned without being pinged again;
+	// discovery picks up at the first target it had not settled
+	if s.discoverer != nil {
+		if cp.HostsThrough == math.MaxUint64 {
+			s.useHosts(cp.Hosts)
+		} else {
+			s.hosts, s.hostsThrough = cp.Hosts, cp.HostsThrough
+		}
+	}
+
+	if total := s.totalTasks(); total != 0 && cp.Total != total {
+		return fmt.Errorf("checkpoint covers %d tasks but targets now expand to %d", cp.Total, total)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tracker = newTaskTracker(cp.Position, cp.Completed)
+	s.skipped = cp.Skipped
+	s.collector.Restore(cp.Results, cp.Summary)
+
+	return nil
+}
+
+// checkpointLoop saves a checkpoint every configured interval until stopped
+func (s *Scanner) checkpointLoop(stop <-chan struct{}, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(s.config.CheckpointInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := s.saveCheckpoint(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+	}
+}
+
+// saveCheckpoint writes the scan's current progress to the checkpoint file
+func (s *Scanner) saveCheckpoint() error {
+	s.mu.Lock()
+	position, completed := s.tracker.snapshot()
+	results := s.collector.GetResults()
+	summary := s.collector.GetSummary()
+	skipped := s.skipped
+	hosts, hostsThrough := s.hosts, s.hostsThrough
+	s.mu.Unlock()
+
+	if s.discovered {
+		hostsThrough = math.MaxUint64
+	}
+
+	cp := &Checkpoint{
+		Version:      checkpointVersion,
+		SavedAt:      time.Now(),
+		Config:       s.config,
+		Total:        s.totalTasks(),
+		Position:     position,
+		Completed:    completed,
+		Skipped:      skipped,
+		Hosts:        hosts,
+		HostsThrough: hostsThrough,
+		Summary:      summary,
+		Results:      results,
+	}
+
+	return cp.Save(s.config.CheckpointFile)
+}
+
+// reportProgress periodically reports scan progress until the scan is done
+func (s *Scanner) reportProgress(ctx context.Context, done <-chan struct{}, wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	ticker := time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
 
-	totalTasks := len(s.targets) * len(s.ports)
-	lastCount := 0
+	totalTasks := s.totalTasks()
+	lastCount := uint64(0)
+	reported := false
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
+		case <-done:
+			if reported {
+				fmt.Println() // New line after progress
+			}
+			return
 		case <-ticker.C:
+			s.mu.Lock()
 			summary := s.collector.GetSummary()
-			currentCount := summary.TotalScanned
+			currentCount := uint64(summary.TotalScanned) + s.skipped
+			s.mu.Unlock()
 			rate := float64(currentCount-lastCount) / 5.0 // scans per second
 
-			progress := float64(currentCount) / float64(totalTasks) * 100
-			
-			fmt.Printf("\rProgress: %d/%d (%.1f%%) | Open: %d | Rate: %.0f scans/sec",
-				currentCount, totalTasks, progress, summary.OpenPorts, rate)
+			// The total is unknown while a target list is being streamed
+			if totalTasks == 0 {
+				fmt.Printf("\rProgress: %d | Open: %d | Rate: %.0f scans/sec",
+					currentCount, summary.OpenPorts, rate)
+			} else {
+				progress := float64(currentCount) / float64(totalTasks) * 100
+				fmt.Printf("\rProgress: %d/%d (%.1f%%) | Open: %d | Rate: %.0f scans/sec",
+					currentCount, totalTasks, progress, summary.OpenPorts, rate)
+			}
 
 			lastCount = currentCount
+			reported = true
 
 			// Stop reporting when done
-			if currentCount >= totalTasks {
+			if totalTasks > 0 && currentCount >= totalTasks {
 				fmt.Println() // New line after progress
 				return
 			}
@@ -198,6 +1050,11 @@ func (s *Scanner) Stop() {
 	}
 }
 
+// GetSeed returns the seed driving randomized ordering and sampling
+func (s *Scanner) GetSeed() uint64 {
+	return s.config.Seed
+}
+
 // GetSummary returns the scan summary
 func (s *Scanner) GetSummary() result.Summary {
 	return s.collector.GetSummary()
@@ -206,4 +1063,4 @@ func (s *Scanner) GetSummary() result.Summary {
 // GetResults returns all scan results
 func (s *Scanner) GetResults() []*result.Result {
 	return s.collector.GetResults()
-}
\ No newline at end of file
+}
@@ -0,0 +1,86 @@
+package scope
+
+import (
+	"net/netip"
+	"sort"
+)
+
+// addrRange is an inclusive range of addresses of one family
+type addrRange struct {
+	first, last netip.Addr
+}
+
+// rangeSet is a union of address ranges, kept sorted and merged so lookups
+// take O(log n) however many entries a scope file holds
+type rangeSet struct {
+	ranges []addrRange
+}
+
+// add inserts a range; normalize must be called before querying the set
+func (s *rangeSet) add(first, last netip.Addr) {
+	s.ranges = append(s.ranges, addrRange{first: first, last: last})
+}
+
+// normalize sorts the ranges and merges those that overlap or touch
+func (s *rangeSet) normalize() {
+	sort.Slice(s.ranges, func(i, j int) bool {
+		return s.ranges[i].first.Less(s.ranges[j].first)
+	})
+
+	merged := s.ranges[:0]
+	for _, r := range s.ranges {
+		if n := len(merged); n > 0 && adjacent(merged[n-1], r) {
+			if merged[n-1].last.Less(r.last) {
+				merged[n-1].last = r.last
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	s.ranges = merged
+}
+
+// adjacent reports whether b, which starts no earlier than a, overlaps or
+// immediately follows a
+func adjacent(a, b addrRange) bool {
+	if a.first.BitLen() != b.first.BitLen() {
+		return false
+	}
+	next := a.last.Next()
+	return !next.IsValid() || b.first.Compare(next) <= 0
+}
+
+// find returns the range that could hold addr: the last one starting at or
+// before it
+func (s *rangeSet) find(addr netip.Addr) (addrRange, bool) {
+	i := sort.Search(len(s.ranges), func(i int) bool {
+		return addr.Less(s.ranges[i].first)
+	}) - 1
+	if i < 0 {
+		return addrRange{}, false
+	}
+	return s.ranges[i], true
+}
+
+// contains reports whether addr lies in the set
+func (s *rangeSet) contains(addr netip.Addr) bool {
+	r, ok := s.find(addr)
+	return ok && r.first.BitLen() == addr.BitLen() && addr.Compare(r.last) <= 0
+}
+
+// covers reports whether every address in [first, last] lies in the set
+func (s *rangeSet) covers(first, last netip.Addr) bool {
+	r, ok := s.find(first)
+	return ok && r.first.BitLen() == first.BitLen() && last.Compare(r.last) <= 0
+}
+
+// prefixRange returns the first and last addresses of a prefix
+func prefixRange(prefix netip.Prefix) (netip.Addr, netip.Addr) {
+	first := prefix.Masked().Addr()
+	b := first.AsSlice()
+	for bit := prefix.Bits(); bit < len(b)*8; bit++ {
+		b[bit/8] |= 0x80 >> (bit % 8)
+	}
+	last, _ := netip.AddrFromSlice(b)
+	return first, last
+}
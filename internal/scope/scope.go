@@ -0,0 +1,320 @@
+package scope
+
+import (
+	"bufio"
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+)
+
+// reservedRanges are never scanned unless explicitly allowed: probes to
+// them are either meaningless or reach hosts other than the one intended
+var reservedRanges = []struct {
+	prefix string
+	reason string
+}{
+	{"0.0.0.0/8", "reserved (\"this network\")"},
+	{"224.0.0.0/4", "multicast"},
+	{"240.0.0.0/4", "reserved"},
+	{"255.255.255.255/32", "broadcast"},
+	{"::/128", "unspecified address"},
+	{"ff00::/8", "multicast"},
+}
+
+// Options controls which networks a Scope permits
+type Options struct {
+	// File lists the networks and hostnames the scan is authorized to
+	// touch, plus denied ranges (empty = everything not denied)
+	File string
+
+	// Exclude lists addresses, CIDR ranges and hostnames to skip
+	Exclude []string
+
+	// ExcludeFile lists further exclusions, one per line
+	ExcludeFile string
+
+	// AllowReserved permits multicast, broadcast and reserved ranges
+	AllowReserved bool
+}
+
+// deny is a denied range or hostname with the reason it is refused
+type deny struct {
+	first, last netip.Addr
+	host        string
+	reason      string
+}
+
+// Scope decides which addresses a scan may send probes to. Denials always
+// win; when any allow entries exist, everything else must match one.
+type Scope struct {
+	allow      rangeSet
+	allowHosts []string
+	restricted bool
+	source     string
+
+	denied    rangeSet
+	denyHosts []string
+	denies    []deny
+}
+
+// New builds a scope from the given options
+func New(opts Options) (*Scope, error) {
+	s := &Scope{}
+
+	if !opts.AllowReserved {
+		for _, r := range reservedRanges {
+			prefix := netip.MustParsePrefix(r.prefix)
+			s.addDeny(prefix, r.reason+" (use -allow-reserved to scan it)")
+		}
+	}
+
+	if opts.File != "" {
+		if err := s.loadScopeFile(opts.File); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, entry := range opts.Exclude {
+		if err := s.exclude(entry, "excluded"); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.ExcludeFile != "" {
+		err := readLines(opts.ExcludeFile, func(line string, lineNum int) error {
+			if err := s.exclude(line, "excluded by "+opts.ExcludeFile); err != nil {
+				return fmt.Errorf("%s:%d: %w", opts.ExcludeFile, lineNum, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.allow.normalize()
+	s.denied.normalize()
+
+	return s, nil
+}
+
+// loadScopeFile reads allow and deny entries. Each line holds an address,
+// CIDR range or hostname ("*.example.com" matches subdomains), optionally
+// preceded by "allow" or "deny"; bare entries are allowed.
+func (s *Scope) loadScopeFile(path string) error {
+	s.restricted = true
+	s.source = path
+
+	return readLines(path, func(line string, lineNum int) error {
+		action, entry := "allow", line
+		if fields := strings.Fields(line); len(fields) == 2 {
+			action, entry = strings.ToLower(fields[0]), fields[1]
+		} else if len(fields) > 2 {
+			return fmt.Errorf("%s:%d: expected [allow|deny] <entry>", path, lineNum)
+		}
+
+		var err error
+		switch action {
+		case "allow":
+			err = s.allowEntry(entry)
+		case "deny":
+			err = s.exclude(entry, "denied by "+path)
+		default:
+			err = fmt.Errorf("unknown action %q (expected allow or deny)", action)
+		}
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", path, lineNum, err)
+		}
+		return nil
+	})
+}
+
+// allowEntry permits an address, range or hostname
+func (s *Scope) allowEntry(entry string) error {
+	if prefix, ok, err := parseEntry(entry); err != nil {
+		return err
+	} else if ok {
+		first, last := prefixRange(prefix)
+		s.allow.add(first, last)
+		return nil
+	}
+	s.allowHosts = append(s.allowHosts, strings.ToLower(entry))
+	return nil
+}
+
+// exclude refuses an address, range or hostname
+func (s *Scope) exclude(entry, reason string) error {
+	if prefix, ok, err := parseEntry(entry); err != nil {
+		return err
+	} else if ok {
+		s.addDeny(prefix, reason)
+		return nil
+	}
+	host := strings.ToLower(entry)
+	s.denyHosts = append(s.denyHosts, host)
+	s.denies = append(s.denies, deny{host: host, reason: reason})
+	return nil
+}
+
+// addDeny refuses every address in a prefix
+func (s *Scope) addDeny(prefix netip.Prefix, reason string) {
+	first, last := prefixRange(prefix)
+	s.denied.add(first, last)
+	s.denies = append(s.denies, deny{first: first, last: last, reason: reason})
+}
+
+// Allows reports whether probes may be sent to addr, which was resolved
+// from hostname if it is non-empty
+func (s *Scope) Allows(addr netip.Addr, hostname string) bool {
+	return s.CheckAddr(addr, hostname) == nil
+}
+
+// CheckAddr returns an error explaining why addr, resolved from hostname
+// if non-empty, is out of scope, or nil if it may be scanned
+func (s *Scope) CheckAddr(addr netip.Addr, hostname string) error {
+	addr = addr.Unmap()
+
+	if s.denied.contains(addr) || (hostname != "" && matchHost(s.denyHosts, hostname)) {
+		return fmt.Errorf("%s is out of scope: %s", describe(addr, hostname), s.denyReason(addr, addr, hostname))
+	}
+
+	if s.restricted && !s.allow.contains(addr) && (hostname == "" || !matchHost(s.allowHosts, hostname)) {
+		return fmt.Errorf("%s is out of scope: not allowed by %s", describe(addr, hostname), s.source)
+	}
+
+	return nil
+}
+
+// CheckPrefix returns an error if a CIDR target cannot be scanned: when
+// every address in it is denied, or when part of it lies outside the
+// allowed ranges. Prefixes only partly denied are accepted; the denied
+// addresses are skipped as the scan runs.
+func (s *Scope) CheckPrefix(prefix netip.Prefix) error {
+	first, last := prefixRange(prefix.Masked())
+	return s.CheckRanges(prefix.String(), []Range{{First: first, Last: last}})
+}
+
+// Range is an inclusive range of addresses of one family
+type Range struct {
+	First, Last netip.Addr
+}
+
+// CheckRanges applies the rules of CheckPrefix to a target made of one or
+// more address ranges, such as 10.0.0.5-10.0.1.20 or 10.0.1-3.1-254: it is
+// refused if every address in it is denied, or if any range lies partly
+// outside the allowed ranges. name identifies the target in errors.
+func (s *Scope) CheckRanges(name string, ranges []Range) error {
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	denied := true
+	for _, r := range ranges {
+		denied = denied && s.denied.covers(r.First.Unmap(), r.Last.Unmap())
+	}
+	if denied {
+		first, last := ranges[0].First.Unmap(), ranges[0].Last.Unmap()
+		return fmt.Errorf("%s is out of scope: %s", name, s.denyReason(first, last, ""))
+	}
+
+	if s.restricted {
+		for _, r := range ranges {
+			if !s.allow.covers(r.First.Unmap(), r.Last.Unmap()) {
+				return fmt.Errorf("%s is out of scope: not entirely within the ranges allowed by %s", name, s.source)
+			}
+		}
+	}
+
+	return nil
+}
+
+// denyReason explains the first denial overlapping [first, last] or
+// matching hostname
+func (s *Scope) denyReason(first, last netip.Addr, hostname string) string {
+	for _, d := range s.denies {
+		if d.host != "" {
+			if hostname != "" && matchHost([]string{d.host}, hostname) {
+				return d.reason
+			}
+			continue
+		}
+		if d.first.BitLen() == first.BitLen() && d.first.Compare(last) <= 0 && first.Compare(d.last) <= 0 {
+			return d.reason
+		}
+	}
+	return "denied"
+}
+
+// describe renders an address along with the hostname it came from
+func describe(addr netip.Addr, hostname string) string {
+	if hostname == "" {
+		return addr.String()
+	}
+	return fmt.Sprintf("%s (%s)", addr, hostname)
+}
+
+// matchHost reports whether hostname matches any pattern; "*.example.com"
+// matches any subdomain of example.com
+func matchHost(patterns []string, hostname string) bool {
+	hostname = strings.TrimSuffix(strings.ToLower(hostname), ".")
+	for _, pattern := range patterns {
+		if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+			if strings.HasSuffix(hostname, suffix) {
+				return true
+			}
+		} else if hostname == strings.TrimSuffix(pattern, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseEntry parses an address or CIDR range as a prefix. It reports
+// false for hostnames and an error for anything else.
+func parseEntry(entry string) (netip.Prefix, bool, error) {
+	if strings.Contains(entry, "/") {
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return netip.Prefix{}, false, fmt.Errorf("invalid CIDR %s: %w", entry, err)
+		}
+		return prefix.Masked(), true, nil
+	}
+
+	if addr, err := netip.ParseAddr(entry); err == nil {
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()), true, nil
+	}
+
+	host := strings.TrimPrefix(entry, "*.")
+	if host == "" || strings.ContainsAny(host, " \t*/:") {
+		return netip.Prefix{}, false, fmt.Errorf("invalid entry: %s (expected IP, CIDR or hostname)", entry)
+	}
+	return netip.Prefix{}, false, nil
+}
+
+// readLines calls fn for each non-empty line of a file, with comments
+// starting at "#" removed
+func readLines(path string, fn func(line string, lineNum int) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		if err := fn(line, lineNum); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return nil
+}
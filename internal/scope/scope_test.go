@@ -0,0 +1,162 @@
+package scope
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFile writes data to a file in a temporary directory and returns
+// its path
+func writeFile(t *testing.T, data string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "scope.txt")
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCheckAddr(t *testing.T) {
+	restricted, err := New(Options{
+		File: writeFile(t, `
+# engagement scope
+allow 10.0.0.0/24
+10.0.1.0/24
+allow *.corp.example
+deny 10.0.0.128/25
+deny db.corp.example
+`),
+		Exclude: []string{"10.0.1.7"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	open, err := New(Options{Exclude: []string{"192.168.0.0/16", "printer.lan"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reserved, err := New(Options{AllowReserved: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		scope    *Scope
+		addr     string
+		hostname string
+		want     bool
+	}{
+		{"allowed range", restricted, "10.0.0.5", "", true},
+		{"merged allowed range", restricted, "10.0.1.200", "", true},
+		{"denied inside allowed", restricted, "10.0.0.200", "", false},
+		{"excluded address", restricted, "10.0.1.7", "", false},
+		{"outside allowed", restricted, "10.0.2.1", "", false},
+		{"allowed hostname", restricted, "172.16.0.1", "web.corp.example", true},
+		{"allowed hostname, trailing dot", restricted, "172.16.0.1", "WEB.corp.example.", true},
+		{"denied hostname", restricted, "10.0.0.9", "db.corp.example", false},
+		{"hostname resolving to denied address", restricted, "10.0.0.200", "web.corp.example", false},
+		{"apex not matched by wildcard", restricted, "172.16.0.1", "corp.example", false},
+		{"mapped address", restricted, "::ffff:10.0.0.5", "", true},
+		{"unrestricted", open, "8.8.8.8", "", true},
+		{"unrestricted exclusion", open, "192.168.4.4", "", false},
+		{"unrestricted hostname exclusion", open, "10.9.9.9", "printer.lan", false},
+		{"multicast", open, "224.0.0.1", "", false},
+		{"broadcast", open, "255.255.255.255", "", false},
+		{"IPv6 multicast", open, "ff02::1", "", false},
+		{"reserved allowed", reserved, "224.0.0.1", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.scope.CheckAddr(netip.MustParseAddr(tt.addr), tt.hostname)
+			if got := err == nil; got != tt.want {
+				t.Errorf("CheckAddr(%s, %q) = %v, want allowed = %v", tt.addr, tt.hostname, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckPrefix(t *testing.T) {
+	s, err := New(Options{File: writeFile(t, "10.0.0.0/24\n10.0.1.0/24\ndeny 10.0.0.128/25\n")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		prefix string
+		want   bool
+	}{
+		{"10.0.0.0/24", true},    // partly denied; denied addresses are skipped
+		{"10.0.0.0/23", true},    // spans both allowed ranges
+		{"10.0.0.128/25", false}, // entirely denied
+		{"10.0.0.0/22", false},   // partly outside the allowed ranges
+		{"192.168.0.0/24", false},
+		{"2001:db8::/64", false},
+	}
+
+	for _, tt := range tests {
+		err := s.CheckPrefix(netip.MustParsePrefix(tt.prefix))
+		if got := err == nil; got != tt.want {
+			t.Errorf("CheckPrefix(%s) = %v, want allowed = %v", tt.prefix, err, tt.want)
+		}
+	}
+}
+
+func TestCheckRanges(t *testing.T) {
+	s, err := New(Options{Exclude: []string{"10.0.0.0/24"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := func(first, last string) Range {
+		return Range{First: netip.MustParseAddr(first), Last: netip.MustParseAddr(last)}
+	}
+
+	tests := []struct {
+		name   string
+		ranges []Range
+		want   bool
+	}{
+		{"every range denied", []Range{r("10.0.0.1", "10.0.0.9"), r("10.0.0.20", "10.0.0.29")}, false},
+		{"one range allowed", []Range{r("10.0.0.1", "10.0.0.9"), r("10.0.1.1", "10.0.1.9")}, true},
+		{"partly denied", []Range{r("10.0.0.250", "10.0.1.5")}, true},
+		{"none", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.CheckRanges(tt.name, tt.ranges)
+			if got := err == nil; got != tt.want {
+				t.Errorf("CheckRanges = %v, want allowed = %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"unknown action", Options{File: writeFile(t, "permit 10.0.0.0/8")}},
+		{"too many fields", Options{File: writeFile(t, "allow 10.0.0.0/8 now")}},
+		{"bad prefix", Options{File: writeFile(t, "10.0.0.0/33")}},
+		{"bad exclusion", Options{Exclude: []string{"10.0.0.0/8/8"}}},
+		{"wildcard in the middle", Options{Exclude: []string{"db.*.example"}}},
+		{"missing file", Options{File: filepath.Join(t.TempDir(), "none.txt")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.opts); err == nil {
+				t.Error("New succeeded")
+			}
+		})
+	}
+}
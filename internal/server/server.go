@@ -0,0 +1,130 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+)
+
+// Host summarizes the results for one scanned address
+type Host struct {
+	IP        string   `json:"ip"`
+	Hostnames []string `json:"hostnames,omitempty"`
+	OpenPorts []int    `json:"open_ports"`
+	Scanned   int      `json:"scanned"`
+}
+
+// Server is a read-only HTTP JSON API over a set of scan results
+type Server struct {
+	report *result.Report
+	mux    *http.ServeMux
+}
+
+// New creates a server for the given results. Routes:
+//
+//	GET /api/summary                 scan statistics
+//	GET /api/hosts                   one entry per address with its open ports
+//	GET /api/results?status=&ip=...  results, filtered by any of ip,
+//	                                 hostname, port, protocol, status, service
+func New(report *result.Report) *Server {
+	s := &Server{
+		report: report,
+		mux:    http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("GET /api/summary", s.handleSummary)
+	s.mux.HandleFunc("GET /api/hosts", s.handleHosts)
+	s.mux.HandleFunc("GET /api/results", s.handleResults)
+
+	return s
+}
+
+// ServeHTTP dispatches a request to the matching route
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleSummary(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.report.Summary)
+}
+
+func (s *Server) handleHosts(w http.ResponseWriter, r *http.Request) {
+	var order []string
+	hosts := make(map[string]*Host)
+
+	for _, res := range s.report.Results {
+		h, ok := hosts[res.IP]
+		if !ok {
+			h = &Host{IP: res.IP, OpenPorts: make([]int, 0)}
+			hosts[res.IP] = h
+			order = append(order, res.IP)
+		}
+
+		h.Scanned++
+		if res.Status == result.StatusOpen {
+			h.OpenPorts = append(h.OpenPorts, res.Port)
+		}
+		if res.Hostname != "" && !contains(h.Hostnames, res.Hostname) {
+			h.Hostnames = append(h.Hostnames, res.Hostname)
+		}
+	}
+
+	list := make([]*Host, 0, len(order))
+	for _, ip := range order {
+		sort.Ints(hosts[ip].OpenPorts)
+		list = append(list, hosts[ip])
+	}
+
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) handleResults(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	port := 0
+	if p := q.Get("port"); p != "" {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid port: " + p})
+			return
+		}
+		port = n
+	}
+
+	matches := make([]*result.Result, 0)
+	for _, res := range s.report.Results {
+		if (q.Has("ip") && res.IP != q.Get("ip")) ||
+			(q.Has("hostname") && res.Hostname != q.Get("hostname")) ||
+			(q.Has("protocol") && res.Protocol != q.Get("protocol")) ||
+			(q.Has("status") && string(res.Status) != q.Get("status")) ||
+			(q.Has("service") && res.Service != q.Get("service")) ||
+			(port != 0 && res.Port != port) {
+			continue
+		}
+		matches = append(matches, res)
+	}
+
+	writeJSON(w, http.StatusOK, matches)
+}
+
+// writeJSON writes v as the JSON response body
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
+
+// contains reports whether list holds s
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
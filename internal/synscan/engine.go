@@ -0,0 +1,440 @@
+package synscan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+	"github.com/JeffreyOmoakah/netscout.git/internal/worker"
+)
+
+// Options controls a SYN scan
+type Options struct {
+	// Timeout is how long to wait for a reply before a port is filtered
+	Timeout time.Duration
+
+	// RTT, when set, replaces the fixed Timeout with one adapted to each
+	// host's measured round-trip time
+	RTT *worker.RTTEstimator
+
+	// MaxInFlight bounds the probes awaiting a reply at once
+	MaxInFlight int
+
+	// Retry decides which unanswered or unsent SYNs are sent again
+	Retry worker.RetryPolicy
+}
+
+// probeKey identifies a probe by what its replies carry: the target's
+// address and port, and the sequence number they acknowledge
+type probeKey struct {
+	addr netip.Addr
+	port uint16
+	seq  uint32
+}
+
+// probe is a SYN awaiting a reply
+type probe struct {
+	task    worker.Task
+	src     netip.Addr
+	sent    time.Time
+	timeout time.Duration
+}
+
+// Engine performs half-open TCP scans over raw sockets, which needs root
+// or CAP_NET_RAW on Linux. A sender loop writes one SYN per task; receiver
+// loops match SYN/ACK and RST replies to probes by address, port and
+// acknowledged sequence number, resetting the connections found open; and
+// an expiry loop reports probes left unanswered as filtered, or queues them
+// to be sent again when the retry policy allows. No goroutine
+// waits on any single probe, so thousands can be outstanding at once.
+//
+// Engine runs tasks like a worker.Pool: Start it, Submit tasks, then Close
+// it to wait for the last replies. Results go to the results channel.
+type Engine struct {
+	opts    Options
+	v4, v6  net.PacketConn
+	port    uint16
+	release func()
+
+	tasks   chan worker.Task
+	retries chan worker.Task
+	results chan<- *result.Result
+	slots   chan struct{}
+
+	mu      sync.Mutex
+	pending map[probeKey]*probe
+
+	// inFlight counts probes registered but not yet reported, including
+	// those waiting to be retried
+	inFlight sync.WaitGroup
+
+	// drained is done once the sender has taken every submitted task
+	drained sync.WaitGroup
+	loops   sync.WaitGroup
+	stop    chan struct{}
+}
+
+// New opens the raw sockets for a SYN scan and reserves the source port
+// its probes are sent from. IPv6 targets fail individually when no IPv6
+// raw socket can be opened.
+func New(opts Options, results chan<- *result.Result) (*Engine, error) {
+	v4, err := listenRaw("ip4:tcp")
+	if err != nil {
+		return nil, err
+	}
+	v6, err := listenRaw("ip6:tcp")
+	if err != nil {
+		v6 = nil
+	}
+
+	port, release, err := reservePort()
+	if err != nil {
+		v4.Close()
+		if v6 != nil {
+			v6.Close()
+		}
+		return nil, err
+	}
+
+	return newEngine(opts, v4, v6, port, release, results), nil
+}
+
+// newEngine builds an engine over sockets that are already open, sending
+// its probes from port. release is called once the engine is closed. v6
+// may be nil.
+func newEngine(opts Options, v4, v6 net.PacketConn, port uint16, release func(), results chan<- *result.Result) *Engine {
+	return &Engine{
+		opts:    opts,
+		v4:      v4,
+		v6:      v6,
+		port:    port,
+		release: release,
+		tasks:   make(chan worker.Task, min(opts.MaxInFlight*10, 10000)),
+		retries: make(chan worker.Task),
+		results: results,
+		slots:   make(chan struct{}, opts.MaxInFlight),
+		pending: make(map[probeKey]*probe),
+		stop:    make(chan struct{}),
+	}
+}
+
+// Start launches the sender, receiver and expiry loops
+func (e *Engine) Start(ctx context.Context) {
+	e.drained.Add(1)
+	e.loops.Add(1)
+	go e.send(ctx)
+
+	for _, conn := range []net.PacketConn{e.v4, e.v6} {
+		if conn != nil {
+			e.loops.Add(1)
+			go e.receive(ctx, conn)
+		}
+	}
+
+	e.loops.Add(1)
+	go e.expire(ctx)
+}
+
+// Submit queues a task to be probed
+func (e *Engine) Submit(ctx context.Context, task worker.Task) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case e.tasks <- task:
+		return nil
+	}
+}
+
+// Close stops accepting tasks, waits until every probe has been answered
+// or has run out of attempts, then releases the sockets
+func (e *Engine) Close() {
+	close(e.tasks)
+	e.drained.Wait()
+	e.inFlight.Wait()
+
+	close(e.stop)
+	e.v4.Close()
+	if e.v6 != nil {
+		e.v6.Close()
+	}
+	e.loops.Wait()
+	e.release()
+}
+
+// send writes a SYN for each queued task and each due retry, waiting for
+// a free slot when MaxInFlight probes are outstanding
+func (e *Engine) send(ctx context.Context) {
+	defer e.loops.Done()
+
+	// Tasks arrive grouped by host, so one route lookup serves many
+	var lastDst, lastSrc netip.Addr
+
+	tasks := e.tasks
+	for {
+		var task worker.Task
+		select {
+		case <-e.stop:
+			return
+		case task = <-e.retries:
+		case t, ok := <-tasks:
+			if !ok {
+				// Keep serving retries until Close stops the loop
+				tasks = nil
+				e.drained.Done()
+				continue
+			}
+			task = t
+		}
+
+		// A retried task is still counted in flight from its last attempt
+		retry := task.Attempts > 0
+
+		select {
+		case <-ctx.Done():
+			if retry {
+				e.inFlight.Done()
+			}
+			continue
+		case e.slots <- struct{}{}:
+		}
+
+		dst, err := netip.ParseAddr(task.IP)
+		if err == nil && dst != lastDst {
+			var src netip.Addr
+			if src, err = sourceAddr(dst); err == nil {
+				lastDst, lastSrc = dst, src
+			}
+		}
+		if err == nil {
+			err = e.sendSYN(task, lastSrc, dst)
+		}
+
+		if err != nil {
+			<-e.slots
+			r := newResult(task, time.Now())
+			r.Status, r.Reason = worker.ClassifyError(err)
+			r.Error = err.Error()
+			if e.opts.Retry.Retry(r) && ctx.Err() == nil {
+				e.inFlight.Add(1)
+				e.retryLater(ctx, task, r.Attempts)
+			} else {
+				e.deliver(ctx, r)
+			}
+		}
+
+		if retry {
+			e.inFlight.Done()
+		}
+	}
+}
+
+// sendSYN registers a probe for a task and writes its SYN
+func (e *Engine) sendSYN(task worker.Task, src, dst netip.Addr) error {
+	conn := e.v4
+	if dst.Is6() {
+		conn = e.v6
+	}
+	if conn == nil {
+		return fmt.Errorf("no IPv6 raw socket available for %s", dst)
+	}
+
+	seq := rand.Uint32()
+	key := probeKey{addr: dst.WithZone(""), port: uint16(task.Port), seq: seq}
+	syn := buildSYN(netip.AddrPortFrom(src, e.port), netip.AddrPortFrom(dst, uint16(task.Port)), seq)
+
+	timeout := e.opts.Timeout
+	if e.opts.RTT != nil {
+		timeout = e.opts.RTT.Timeout(task.IP, task.Attempts)
+	}
+
+	// Register before sending, as the reply may beat WriteTo returning
+	e.mu.Lock()
+	e.pending[key] = &probe{task: task, src: src, sent: time.Now(), timeout: timeout}
+	e.inFlight.Add(1)
+	e.mu.Unlock()
+
+	if _, err := conn.WriteTo(syn, &net.IPAddr{IP: dst.AsSlice(), Zone: dst.Zone()}); err != nil {
+		if e.take(key) != nil {
+			e.inFlight.Done()
+			return fmt.Errorf("failed to send SYN: %w", err)
+		}
+		// A reply already claimed the probe, so the SYN did get out
+	}
+	return nil
+}
+
+// receive matches the TCP segments arriving on a raw socket to probes
+func (e *Engine) receive(ctx context.Context, conn net.PacketConn) {
+	defer e.loops.Done()
+
+	buf := make([]byte, 1500)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+
+		seg, ok := parseSegment(buf[:n])
+		if !ok || seg.dstPort != e.port {
+			continue
+		}
+		open := seg.flags&(flagSYN|flagACK) == flagSYN|flagACK
+		if !open && seg.flags&flagRST == 0 {
+			continue
+		}
+
+		ipAddr, ok := from.(*net.IPAddr)
+		if !ok {
+			continue
+		}
+		src, ok := netip.AddrFromSlice(ipAddr.IP)
+		if !ok {
+			continue
+		}
+
+		// Replies acknowledge the sequence number after the SYN's
+		p := e.take(probeKey{addr: src.Unmap(), port: seg.srcPort, seq: seg.ack - 1})
+		if p == nil {
+			continue
+		}
+
+		r := newResult(p.task, p.sent)
+		if e.opts.RTT != nil {
+			// Each attempt has its own sequence number, so even replies to
+			// retries time a single round trip
+			e.opts.RTT.Observe(p.task.IP, r.Duration)
+		}
+		if open {
+			r.Status, r.Reason = result.StatusOpen, result.ReasonSynAck
+			dst := netip.AddrPortFrom(src.Unmap().WithZone(ipAddr.Zone), seg.srcPort)
+			rst := buildRST(netip.AddrPortFrom(p.src, e.port), dst, seg.ack)
+			conn.WriteTo(rst, ipAddr)
+		} else {
+			r.Status, r.Reason = result.StatusClosed, result.ReasonReset
+		}
+		e.finish(ctx, r)
+	}
+}
+
+// expire reports probes that drew no reply within their timeout as
+// filtered. Once the scan is cancelled it drops every outstanding probe
+// instead.
+func (e *Engine) expire(ctx context.Context) {
+	defer e.loops.Done()
+
+	shortest := e.opts.Timeout
+	if e.opts.RTT != nil {
+		shortest = min(shortest, e.opts.RTT.Min)
+	}
+	ticker := time.NewTicker(max(min(shortest/4, 100*time.Millisecond), time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.stop:
+			return
+		case now := <-ticker.C:
+			var expired []*probe
+			e.mu.Lock()
+			for key, p := range e.pending {
+				if ctx.Err() != nil || now.Sub(p.sent) > p.timeout {
+					delete(e.pending, key)
+					expired = append(expired, p)
+				}
+			}
+			e.mu.Unlock()
+
+			for _, p := range expired {
+				r := newResult(p.task, p.sent)
+				r.Status, r.Reason = result.StatusFiltered, result.ReasonNoResponse
+				if e.opts.Retry.Retry(r) && ctx.Err() == nil {
+					// The probe stays in flight until its retry is sent
+					<-e.slots
+					e.retryLater(ctx, p.task, r.Attempts)
+					continue
+				}
+				e.finish(ctx, r)
+			}
+		}
+	}
+}
+
+// retryLater queues a task to be sent again once the backoff delay after
+// its last attempt has passed and the rate limit allows. The caller holds
+// an inFlight count for it, which the sender releases.
+func (e *Engine) retryLater(ctx context.Context, task worker.Task, attempts int) {
+	task.Attempts = attempts
+	time.AfterFunc(e.opts.Retry.Delay(attempts), func() {
+		if !e.opts.Retry.Pace(ctx) {
+			e.inFlight.Done()
+			return
+		}
+		select {
+		case e.retries <- task:
+		case <-ctx.Done():
+			e.inFlight.Done()
+		}
+	})
+}
+
+// take removes and returns a pending probe, or nil if it is not pending
+func (e *Engine) take(key probeKey) *probe {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	p := e.pending[key]
+	delete(e.pending, key)
+	return p
+}
+
+// finish reports the result of a probe taken from the pending set and
+// frees its slot
+func (e *Engine) finish(ctx context.Context, r *result.Result) {
+	e.deliver(ctx, r)
+	<-e.slots
+	e.inFlight.Done()
+}
+
+// deliver forwards a result unless the scan was cancelled, as probes
+// interrupted by cancellation carry no useful information
+func (e *Engine) deliver(ctx context.Context, r *result.Result) {
+	if ctx.Err() != nil {
+		return
+	}
+	e.results <- r
+}
+
+// newResult starts the result of a probe sent at the given time
+func newResult(task worker.Task, sent time.Time) *result.Result {
+	return &result.Result{
+		IP:        task.IP,
+		Hostname:  task.Hostname,
+		Port:      task.Port,
+		Protocol:  "tcp",
+		Timestamp: sent,
+		Duration:  time.Since(sent),
+		Seq:       task.Seq,
+		Attempts:  task.Attempts + 1,
+	}
+}
+
+// sourceAddr returns the local address the kernel sends packets to dst
+// from. Connecting a UDP socket picks the route without sending anything.
+func sourceAddr(dst netip.Addr) (netip.Addr, error) {
+	conn, err := net.DialUDP("udp", nil, net.UDPAddrFromAddrPort(netip.AddrPortFrom(dst, 9)))
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("no route to %s: %w", dst, err)
+	}
+	defer conn.Close()
+
+	return conn.LocalAddr().(*net.UDPAddr).AddrPort().Addr().Unmap(), nil
+}
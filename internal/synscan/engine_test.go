@@ -0,0 +1,233 @@
+package synscan
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+	"github.com/JeffreyOmoakah/netscout.git/internal/worker"
+)
+
+// How the stand-in network answers a SYN to a port
+const (
+	answerOpen     = "open"     // SYN/ACK
+	answerClosed   = "closed"   // RST
+	answerSilent   = "silent"   // nothing
+	answerLate     = "late"     // nothing to the first SYN, SYN/ACK after
+	answerWrongAck = "wrongack" // SYN/ACK acknowledging another sequence
+)
+
+// reply is a segment the stand-in network delivers to the engine
+type reply struct {
+	data []byte
+	from net.Addr
+}
+
+// fakeNet stands in for a raw socket, answering the engine's SYNs as
+// configured per destination port
+type fakeNet struct {
+	answers map[uint16]string
+
+	mu   sync.Mutex
+	syns map[uint16]int
+	rsts map[uint16]int
+
+	replies chan reply
+	closed  chan struct{}
+	once    sync.Once
+}
+
+func newFakeNet(answers map[uint16]string) *fakeNet {
+	return &fakeNet{
+		answers: answers,
+		syns:    make(map[uint16]int),
+		rsts:    make(map[uint16]int),
+		replies: make(chan reply, 100),
+		closed:  make(chan struct{}),
+	}
+}
+
+func (f *fakeNet) WriteTo(b []byte, addr net.Addr) (int, error) {
+	seg, ok := parseSegment(b)
+	if !ok {
+		return 0, os.ErrInvalid
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if seg.flags&flagRST != 0 {
+		f.rsts[seg.dstPort]++
+		return len(b), nil
+	}
+
+	f.syns[seg.dstPort]++
+	ack, flags := seg.seq+1, byte(0)
+	switch f.answers[seg.dstPort] {
+	case answerOpen:
+		flags = flagSYN | flagACK
+	case answerClosed:
+		flags = flagRST | flagACK
+	case answerLate:
+		if f.syns[seg.dstPort] > 1 {
+			flags = flagSYN | flagACK
+		}
+	case answerWrongAck:
+		ack, flags = seg.seq+2, flagSYN|flagACK
+	}
+	if flags == 0 {
+		return len(b), nil
+	}
+
+	r := make([]byte, tcpHeaderLen)
+	binary.BigEndian.PutUint16(r[0:2], seg.dstPort)
+	binary.BigEndian.PutUint16(r[2:4], seg.srcPort)
+	binary.BigEndian.PutUint32(r[8:12], ack)
+	r[12] = tcpHeaderLen / 4 << 4
+	r[13] = flags
+	f.replies <- reply{data: r, from: addr}
+	return len(b), nil
+}
+
+func (f *fakeNet) ReadFrom(b []byte) (int, net.Addr, error) {
+	select {
+	case r := <-f.replies:
+		return copy(b, r.data), r.from, nil
+	case <-f.closed:
+		return 0, nil, net.ErrClosed
+	}
+}
+
+func (f *fakeNet) Close() error {
+	f.once.Do(func() { close(f.closed) })
+	return nil
+}
+
+func (f *fakeNet) LocalAddr() net.Addr                { return &net.IPAddr{IP: net.IPv4zero} }
+func (f *fakeNet) SetDeadline(t time.Time) error      { return nil }
+func (f *fakeNet) SetReadDeadline(t time.Time) error  { return nil }
+func (f *fakeNet) SetWriteDeadline(t time.Time) error { return nil }
+
+// scan runs every task through an engine and returns the results by port
+func scan(t *testing.T, e *Engine, results chan *result.Result, tasks []worker.Task) map[int]*result.Result {
+	t.Helper()
+
+	e.Start(context.Background())
+	for _, task := range tasks {
+		if err := e.Submit(context.Background(), task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	e.Close()
+	close(results)
+
+	byPort := make(map[int]*result.Result)
+	for r := range results {
+		if byPort[r.Port] != nil {
+			t.Errorf("port %d reported twice", r.Port)
+		}
+		byPort[r.Port] = r
+	}
+	return byPort
+}
+
+func TestEngine(t *testing.T) {
+	tests := []struct {
+		port     uint16
+		answer   string
+		status   result.Status
+		reason   string
+		attempts int
+		rst      bool
+	}{
+		{1001, answerOpen, result.StatusOpen, result.ReasonSynAck, 1, true},
+		{1002, answerClosed, result.StatusClosed, result.ReasonReset, 1, false},
+		{1003, answerSilent, result.StatusFiltered, result.ReasonNoResponse, 2, false},
+		{1004, answerLate, result.StatusOpen, result.ReasonSynAck, 2, true},
+		{1005, answerWrongAck, result.StatusFiltered, result.ReasonNoResponse, 2, false},
+	}
+
+	answers := make(map[uint16]string)
+	var tasks []worker.Task
+	for i, tt := range tests {
+		answers[tt.port] = tt.answer
+		tasks = append(tasks, worker.Task{IP: "127.0.0.1", Port: int(tt.port), Seq: uint64(i)})
+	}
+
+	net4 := newFakeNet(answers)
+	results := make(chan *result.Result, len(tests))
+	opts := Options{
+		Timeout:     50 * time.Millisecond,
+		MaxInFlight: 2,
+		Retry:       worker.RetryPolicy{Retries: 1, Backoff: 10 * time.Millisecond},
+	}
+	e := newEngine(opts, net4, nil, 40000, func() {}, results)
+
+	byPort := scan(t, e, results, tasks)
+	for _, tt := range tests {
+		r := byPort[int(tt.port)]
+		if r == nil {
+			t.Errorf("port %d (%s): no result", tt.port, tt.answer)
+			continue
+		}
+		if r.Status != tt.status || r.Reason != tt.reason {
+			t.Errorf("port %d (%s): got %s (%s), want %s (%s)", tt.port, tt.answer, r.Status, r.Reason, tt.status, tt.reason)
+		}
+		if r.Attempts != tt.attempts {
+			t.Errorf("port %d (%s): attempts = %d, want %d", tt.port, tt.answer, r.Attempts, tt.attempts)
+		}
+		if got := net4.rsts[tt.port] > 0; got != tt.rst {
+			t.Errorf("port %d (%s): reset sent = %v, want %v", tt.port, tt.answer, got, tt.rst)
+		}
+	}
+}
+
+func TestEngineNoIPv6Socket(t *testing.T) {
+	results := make(chan *result.Result, 1)
+	e := newEngine(Options{Timeout: 50 * time.Millisecond, MaxInFlight: 1}, newFakeNet(nil), nil, 40000, func() {}, results)
+
+	r := scan(t, e, results, []worker.Task{{IP: "::1", Port: 80}})[80]
+	if r == nil || r.Status != result.StatusError {
+		t.Errorf("result = %+v, want an error", r)
+	}
+}
+
+// TestEngineLoopback scans real ports on loopback over raw sockets, which
+// needs root or CAP_NET_RAW
+func TestEngineLoopback(t *testing.T) {
+	results := make(chan *result.Result, 2)
+	e, err := New(Options{Timeout: time.Second, MaxInFlight: 2}, results)
+	if err != nil {
+		t.Skipf("raw sockets unavailable: %v", err)
+	}
+
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	open := ln.Addr().(*net.TCPAddr).Port
+
+	closedLn, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := closedLn.Addr().(*net.TCPAddr).Port
+	closedLn.Close()
+
+	byPort := scan(t, e, results, []worker.Task{
+		{IP: "127.0.0.1", Port: open},
+		{IP: "127.0.0.1", Port: closed, Seq: 1},
+	})
+	if r := byPort[open]; r == nil || r.Status != result.StatusOpen {
+		t.Errorf("listening port: %+v, want open", r)
+	}
+	if r := byPort[closed]; r == nil || r.Status != result.StatusClosed {
+		t.Errorf("closed port: %+v, want closed", r)
+	}
+}
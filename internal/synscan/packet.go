@@ -0,0 +1,96 @@
+package synscan
+
+import (
+	"encoding/binary"
+	"net/netip"
+)
+
+// TCP header flags
+const (
+	flagSYN = 0x02
+	flagRST = 0x04
+	flagACK = 0x10
+)
+
+// tcpHeaderLen is the length of a TCP header without options
+const tcpHeaderLen = 20
+
+// segment holds the TCP header fields a reply is matched on
+type segment struct {
+	srcPort, dstPort uint16
+	seq, ack         uint32
+	flags            byte
+}
+
+// parseSegment reads the header of a TCP segment
+func parseSegment(b []byte) (segment, bool) {
+	if len(b) < tcpHeaderLen {
+		return segment{}, false
+	}
+	return segment{
+		srcPort: binary.BigEndian.Uint16(b[0:2]),
+		dstPort: binary.BigEndian.Uint16(b[2:4]),
+		seq:     binary.BigEndian.Uint32(b[4:8]),
+		ack:     binary.BigEndian.Uint32(b[8:12]),
+		flags:   b[13],
+	}, true
+}
+
+// buildSYN builds a SYN segment opening a connection from src to dst. It
+// carries an MSS option, as SYNs without one stand out to some filters.
+func buildSYN(src, dst netip.AddrPort, seq uint32) []byte {
+	b := make([]byte, tcpHeaderLen+4)
+	writeHeader(b, src, dst, seq, flagSYN)
+	b[12] = byte(len(b)/4) << 4 // data offset in 32-bit words
+
+	// Maximum segment size option: kind 2, length 4, 1460 bytes
+	b[20], b[21] = 2, 4
+	binary.BigEndian.PutUint16(b[22:24], 1460)
+
+	binary.BigEndian.PutUint16(b[16:18], checksum(src.Addr(), dst.Addr(), b))
+	return b
+}
+
+// buildRST builds the reset that tears down a half-open connection after
+// a SYN/ACK, so the target does not keep retransmitting it
+func buildRST(src, dst netip.AddrPort, seq uint32) []byte {
+	b := make([]byte, tcpHeaderLen)
+	writeHeader(b, src, dst, seq, flagRST)
+	b[12] = byte(len(b)/4) << 4
+	binary.BigEndian.PutUint16(b[16:18], checksum(src.Addr(), dst.Addr(), b))
+	return b
+}
+
+// writeHeader fills in the fixed part of a TCP header with no
+// acknowledgement, leaving the data offset and checksum to the caller
+func writeHeader(b []byte, src, dst netip.AddrPort, seq uint32, flags byte) {
+	binary.BigEndian.PutUint16(b[0:2], src.Port())
+	binary.BigEndian.PutUint16(b[2:4], dst.Port())
+	binary.BigEndian.PutUint32(b[4:8], seq)
+	b[13] = flags
+	binary.BigEndian.PutUint16(b[14:16], 1024) // window
+}
+
+// checksum computes the TCP checksum of a segment, covering the IPv4 or
+// IPv6 pseudo-header of the addresses it travels between
+func checksum(src, dst netip.Addr, segment []byte) uint16 {
+	var sum uint32
+	add := func(b []byte) {
+		for i := 0; i+1 < len(b); i += 2 {
+			sum += uint32(binary.BigEndian.Uint16(b[i:]))
+		}
+		if len(b)%2 == 1 {
+			sum += uint32(b[len(b)-1]) << 8
+		}
+	}
+
+	add(src.AsSlice())
+	add(dst.AsSlice())
+	sum += uint32(len(segment)) + 6 // length and protocol number (TCP)
+	add(segment)
+
+	for sum > 0xffff {
+		sum = sum>>16 + sum&0xffff
+	}
+	return ^uint16(sum)
+}
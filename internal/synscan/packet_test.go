@@ -0,0 +1,56 @@
+package synscan
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestBuildSegments(t *testing.T) {
+	tests := []struct {
+		name     string
+		src, dst string
+		build    func(src, dst netip.AddrPort, seq uint32) []byte
+		flags    byte
+		length   int
+	}{
+		{"SYN over IPv4", "10.0.0.1:40000", "10.0.0.2:443", buildSYN, flagSYN, 24},
+		{"SYN over IPv6", "[fd00::1]:40000", "[fd00::2]:22", buildSYN, flagSYN, 24},
+		{"RST over IPv4", "10.0.0.1:40000", "10.0.0.2:443", buildRST, flagRST, 20},
+		{"RST over IPv6", "[fd00::1]:40000", "[fd00::2]:22", buildRST, flagRST, 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, dst := netip.MustParseAddrPort(tt.src), netip.MustParseAddrPort(tt.dst)
+			b := tt.build(src, dst, 0x12345678)
+
+			if len(b) != tt.length {
+				t.Fatalf("length = %d, want %d", len(b), tt.length)
+			}
+			if offset := int(b[12]>>4) * 4; offset != tt.length {
+				t.Errorf("data offset = %d, want %d", offset, tt.length)
+			}
+
+			seg, ok := parseSegment(b)
+			if !ok {
+				t.Fatal("parseSegment rejected the segment")
+			}
+			want := segment{srcPort: src.Port(), dstPort: dst.Port(), seq: 0x12345678, flags: tt.flags}
+			if seg != want {
+				t.Errorf("segment = %+v, want %+v", seg, want)
+			}
+
+			// Summed with its own checksum in place, a valid segment's
+			// checksum comes out as zero
+			if sum := checksum(src.Addr(), dst.Addr(), b); sum != 0 {
+				t.Errorf("checksum does not verify: %#04x", sum)
+			}
+		})
+	}
+}
+
+func TestParseSegmentShort(t *testing.T) {
+	if _, ok := parseSegment(make([]byte, tcpHeaderLen-1)); ok {
+		t.Error("parseSegment accepted a truncated header")
+	}
+}
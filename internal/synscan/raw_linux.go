@@ -0,0 +1,70 @@
+//go:build linux
+
+package synscan
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// rawReadBuffer is the receive buffer size requested for raw sockets
+const rawReadBuffer = 8 << 20
+
+// listenRaw opens a raw TCP socket for one address family ("ip4:tcp" or
+// "ip6:tcp"). It sees a copy of every TCP segment the host receives, and
+// the kernel supplies the IP header of segments written to it.
+func listenRaw(network string) (net.PacketConn, error) {
+	addr := "0.0.0.0"
+	if network == "ip6:tcp" {
+		addr = "::"
+	}
+
+	conn, err := net.ListenPacket(network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open raw socket (SYN scans require root or CAP_NET_RAW): %w", err)
+	}
+
+	// Every TCP segment the host receives is queued here, so a small buffer
+	// overflows and loses replies during fast scans
+	conn.(*net.IPConn).SetReadBuffer(rawReadBuffer)
+	return conn, nil
+}
+
+// reservePort binds, without listening, a TCP socket to a free port so no
+// other connection on this host is given the port probes are sent from.
+// The kernel still resets any SYN/ACK reaching the port.
+func reservePort() (uint16, func(), error) {
+	fd, err := syscall.Socket(syscall.AF_INET6, syscall.SOCK_STREAM|syscall.SOCK_CLOEXEC, 0)
+	var sa syscall.Sockaddr = &syscall.SockaddrInet6{}
+	if err == nil {
+		// Reserve the port for IPv4 as well
+		syscall.SetsockoptInt(fd, syscall.IPPROTO_IPV6, syscall.IPV6_V6ONLY, 0)
+	} else {
+		fd, err = syscall.Socket(syscall.AF_INET, syscall.SOCK_STREAM|syscall.SOCK_CLOEXEC, 0)
+		sa = &syscall.SockaddrInet4{}
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to reserve source port: %w", err)
+	}
+
+	if err := syscall.Bind(fd, sa); err != nil {
+		syscall.Close(fd)
+		return 0, nil, fmt.Errorf("failed to reserve source port: %w", err)
+	}
+
+	bound, err := syscall.Getsockname(fd)
+	if err != nil {
+		syscall.Close(fd)
+		return 0, nil, fmt.Errorf("failed to reserve source port: %w", err)
+	}
+
+	var port int
+	switch sa := bound.(type) {
+	case *syscall.SockaddrInet6:
+		port = sa.Port
+	case *syscall.SockaddrInet4:
+		port = sa.Port
+	}
+	return uint16(port), func() { syscall.Close(fd) }, nil
+}
@@ -0,0 +1,22 @@
+//go:build !linux
+
+package synscan
+
+import (
+	"errors"
+	"net"
+)
+
+// errUnsupported is returned where raw TCP sockets do not deliver replies
+// to user space, as on the BSDs and Windows
+var errUnsupported = errors.New("SYN scans are only supported on Linux")
+
+// listenRaw reports that raw TCP sockets are unavailable
+func listenRaw(network string) (net.PacketConn, error) {
+	return nil, errUnsupported
+}
+
+// reservePort reports that raw TCP sockets are unavailable
+func reservePort() (uint16, func(), error) {
+	return 0, nil, errUnsupported
+}
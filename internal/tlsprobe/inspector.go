@@ -0,0 +1,201 @@
+package tlsprobe
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+)
+
+// tlsPorts lists ports where services conventionally speak TLS from the
+// first byte
+var tlsPorts = map[int]bool{
+	261: true, 443: true, 465: true, 563: true, 585: true, 614: true,
+	636: true, 853: true, 989: true, 990: true, 992: true, 993: true,
+	994: true, 995: true, 1443: true, 2083: true, 2087: true, 2096: true,
+	2376: true, 3269: true, 4443: true, 5061: true, 5986: true, 6443: true,
+	6697: true, 8443: true, 8883: true, 9443: true, 10250: true,
+}
+
+// protocolVersions lists the versions tried when enumerating support
+var protocolVersions = []uint16{
+	tls.VersionTLS10,
+	tls.VersionTLS11,
+	tls.VersionTLS12,
+	tls.VersionTLS13,
+}
+
+// Inspector performs TLS handshakes against open ports and records the
+// negotiated parameters and certificate chain
+type Inspector struct {
+	// Timeout bounds each handshake, including the TCP connect
+	Timeout time.Duration
+
+	// AllPorts attempts a handshake on every open port rather than only
+	// well-known TLS ports and services identified as TLS
+	AllPorts bool
+
+	// EnumerateVersions performs an extra handshake per protocol version
+	// to list which versions the server accepts
+	EnumerateVersions bool
+}
+
+// NewInspector creates a new TLS inspector
+func NewInspector(timeout time.Duration, allPorts, enumerateVersions bool) *Inspector {
+	return &Inspector{
+		Timeout:           timeout,
+		AllPorts:          allPorts,
+		EnumerateVersions: enumerateVersions,
+	}
+}
+
+// Enrich attaches TLS details to an open TCP result when the port
+// completes a handshake
+func (i *Inspector) Enrich(ctx context.Context, r *result.Result) {
+	if r.Protocol != "tcp" || r.Status != result.StatusOpen {
+		return
+	}
+	if !i.AllPorts && !isTLSCandidate(r) {
+		return
+	}
+
+	state, err := i.handshake(ctx, r, tls.VersionTLS10, tls.VersionTLS13)
+	if err != nil {
+		return
+	}
+
+	info := &result.TLSInfo{
+		Version:     tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+	}
+	for _, cert := range state.PeerCertificates {
+		info.Certificates = append(info.Certificates, describe(cert))
+	}
+
+	if i.EnumerateVersions {
+		for _, v := range protocolVersions {
+			if ctx.Err() != nil {
+				break
+			}
+			if _, err := i.handshake(ctx, r, v, v); err == nil {
+				info.SupportedVersions = append(info.SupportedVersions, tls.VersionName(v))
+			}
+		}
+	}
+
+	r.TLS = info
+}
+
+// isTLSCandidate reports whether a result is likely to speak TLS
+func isTLSCandidate(r *result.Result) bool {
+	if tlsPorts[r.Port] {
+		return true
+	}
+	service := strings.ToLower(r.Service)
+	return strings.Contains(service, "ssl") || strings.Contains(service, "tls") || service == "https"
+}
+
+// handshake connects to the result's address and completes a TLS handshake
+// restricted to the given version range
+func (i *Inspector) handshake(ctx context.Context, r *result.Result, minVersion, maxVersion uint16) (tls.ConnectionState, error) {
+	ctx, cancel := context.WithTimeout(ctx, i.Timeout)
+	defer cancel()
+
+	address := net.JoinHostPort(r.IP, strconv.Itoa(r.Port))
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return tls.ConnectionState{}, err
+	}
+	defer conn.Close()
+
+	cfg := &tls.Config{
+		// We are inspecting certificates, not trusting them
+		InsecureSkipVerify: true,
+		MinVersion:         minVersion,
+		MaxVersion:         maxVersion,
+		CipherSuites:       allCipherSuites(),
+		ServerName:         serverName(r),
+	}
+
+	client := tls.Client(conn, cfg)
+	if err := client.HandshakeContext(ctx); err != nil {
+		return tls.ConnectionState{}, err
+	}
+
+	return client.ConnectionState(), nil
+}
+
+// serverName picks the SNI value for a result, preferring the hostname the
+// target was resolved from
+func serverName(r *result.Result) string {
+	if r.Hostname != "" {
+		return r.Hostname
+	}
+	return r.IP
+}
+
+// allCipherSuites offers every suite Go implements, including insecure
+// ones, so servers that only accept weak ciphers still complete a handshake
+func allCipherSuites() []uint16 {
+	var ids []uint16
+	for _, s := range tls.CipherSuites() {
+		ids = append(ids, s.ID)
+	}
+	for _, s := range tls.InsecureCipherSuites() {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
+// describe summarizes a certificate for output
+func describe(cert *x509.Certificate) result.Certificate {
+	c := result.Certificate{
+		Subject:            cert.Subject.String(),
+		Issuer:             cert.Issuer.String(),
+		Serial:             fmt.Sprintf("%X", cert.SerialNumber),
+		NotBefore:          cert.NotBefore,
+		NotAfter:           cert.NotAfter,
+		SignatureAlgorithm: cert.SignatureAlgorithm.String(),
+		SelfSigned:         isSelfSigned(cert),
+	}
+
+	c.SANs = append(c.SANs, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		c.SANs = append(c.SANs, ip.String())
+	}
+	c.SANs = append(c.SANs, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		c.SANs = append(c.SANs, uri.String())
+	}
+
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		c.KeyType, c.KeySize = "RSA", key.N.BitLen()
+	case *ecdsa.PublicKey:
+		c.KeyType, c.KeySize = "ECDSA", key.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		c.KeyType, c.KeySize = "Ed25519", 256
+	default:
+		c.KeyType = cert.PublicKeyAlgorithm.String()
+	}
+
+	return c
+}
+
+// isSelfSigned reports whether a certificate is signed by its own key
+func isSelfSigned(cert *x509.Certificate) bool {
+	if cert.Subject.String() != cert.Issuer.String() {
+		return false
+	}
+	return cert.CheckSignatureFrom(cert) == nil
+}
@@ -0,0 +1,109 @@
+package tlsprobe
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+)
+
+func TestEnrich(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	tlsServer := httptest.NewTLSServer(handler)
+	defer tlsServer.Close()
+
+	plainServer := httptest.NewServer(handler)
+	defer plainServer.Close()
+
+	tests := []struct {
+		name     string
+		server   *httptest.Server
+		allPorts bool
+		service  string
+		wantTLS  bool
+	}{
+		{"TLS on any port", tlsServer, true, "", true},
+		{"TLS service", tlsServer, false, "https", true},
+		{"unlikely port skipped", tlsServer, false, "", false},
+		{"plaintext", plainServer, true, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := tt.server.Listener.Addr().(*net.TCPAddr)
+			r := &result.Result{IP: addr.IP.String(), Port: addr.Port, Protocol: "tcp", Status: result.StatusOpen, Service: tt.service}
+
+			NewInspector(2*time.Second, tt.allPorts, false).Enrich(context.Background(), r)
+			if (r.TLS != nil) != tt.wantTLS {
+				t.Fatalf("TLS = %+v, want present = %v", r.TLS, tt.wantTLS)
+			}
+		})
+	}
+}
+
+func TestEnrichCertificate(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	addr := server.Listener.Addr().(*net.TCPAddr)
+	r := &result.Result{IP: addr.IP.String(), Port: addr.Port, Protocol: "tcp", Status: result.StatusOpen}
+	NewInspector(2*time.Second, true, true).Enrich(context.Background(), r)
+
+	leaf := r.TLS.Leaf()
+	if leaf == nil {
+		t.Fatal("no certificate recorded")
+	}
+
+	want := server.Certificate()
+	if !leaf.NotAfter.Equal(want.NotAfter) {
+		t.Errorf("NotAfter = %v, want %v", leaf.NotAfter, want.NotAfter)
+	}
+	if !slices.Contains(leaf.SANs, "127.0.0.1") || !slices.Contains(leaf.SANs, "example.com") {
+		t.Errorf("SANs = %v, want 127.0.0.1 and example.com", leaf.SANs)
+	}
+	if !leaf.SelfSigned {
+		t.Error("SelfSigned = false, want true")
+	}
+	if leaf.KeyType == "" {
+		t.Error("KeyType is empty")
+	}
+
+	// The server refuses anything older than TLS 1.2
+	if got := r.TLS.SupportedVersions; !slices.Equal(got, []string{"TLS 1.2", "TLS 1.3"}) {
+		t.Errorf("SupportedVersions = %v, want [TLS 1.2 TLS 1.3]", got)
+	}
+}
+
+func TestIsTLSCandidate(t *testing.T) {
+	tests := []struct {
+		port    int
+		service string
+		want    bool
+	}{
+		{443, "", true},
+		{8443, "", true},
+		{8080, "", false},
+		{8080, "https", true},
+		{5000, "ssl/http", true},
+		{25, "smtp", false},
+	}
+
+	for _, tt := range tests {
+		r := &result.Result{Port: tt.port, Service: tt.service}
+		if got := isTLSCandidate(r); got != tt.want {
+			t.Errorf("isTLSCandidate(%d, %q) = %v, want %v", tt.port, tt.service, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package worker
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// BannerOptions controls banner grabbing on open TCP connections
+type BannerOptions struct {
+	// ReadTimeout is how long to wait for the service to send data
+	ReadTimeout time.Duration
+
+	// Probe is sent when the service stays silent (nil = passive only)
+	Probe []byte
+
+	// MaxBytes caps how much of the banner is kept
+	MaxBytes int
+}
+
+// grabBanner reads the service greeting from an open connection. Services
+// that wait for the client to speak first are nudged with the probe string.
+func grabBanner(conn net.Conn, opts *BannerOptions) string {
+	data := readBanner(conn, opts)
+	if len(data) == 0 && len(opts.Probe) > 0 {
+		conn.SetWriteDeadline(time.Now().Add(opts.ReadTimeout))
+		if _, err := conn.Write(opts.Probe); err == nil {
+			data = readBanner(conn, opts)
+		}
+	}
+	return SanitizeBanner(data)
+}
+
+// readBanner reads up to MaxBytes until the read window closes
+func readBanner(conn net.Conn, opts *BannerOptions) []byte {
+	buf := make([]byte, opts.MaxBytes)
+	conn.SetReadDeadline(time.Now().Add(opts.ReadTimeout))
+
+	n := 0
+	for n < len(buf) {
+		m, err := conn.Read(buf[n:])
+		n += m
+		if err != nil {
+			break
+		}
+	}
+	return buf[:n]
+}
+
+// SanitizeBanner renders raw service output as a single printable line,
+// escaping control characters and non-ASCII bytes
+func SanitizeBanner(data []byte) string {
+	// Trailing line endings carry no information
+	data = bytes.TrimRight(data, " \t\r\n\x00")
+
+	var sb strings.Builder
+	for _, b := range data {
+		switch {
+		case b == '\r':
+			sb.WriteString(`\r`)
+		case b == '\n':
+			sb.WriteString(`\n`)
+		case b == '\t':
+			sb.WriteString(`\t`)
+		case b == '\\':
+			sb.WriteString(`\\`)
+		case b >= 0x20 && b < 0x7f:
+			sb.WriteByte(b)
+		default:
+			fmt.Fprintf(&sb, `\x%02x`, b)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,119 @@
+package worker
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+)
+
+// listenTCP starts a loopback TCP listener that runs serve on each
+// connection it accepts. It returns the listener's port.
+func listenTCP(t *testing.T, serve func(net.Conn)) int {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				serve(conn)
+			}()
+		}
+	}()
+
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestSanitizeBanner(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"plain", "SSH-2.0-OpenSSH_9.6", "SSH-2.0-OpenSSH_9.6"},
+		{"trailing line ending", "220 ready\r\n", "220 ready"},
+		{"inner line endings", "a\r\nb\tc", `a\r\nb\tc`},
+		{"backslash", `C:\`, `C:\\`},
+		{"control and non-ASCII", "\x01ok\xff", `\x01ok\xff`},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeBanner([]byte(tt.data)); got != tt.want {
+				t.Errorf("SanitizeBanner(%q) = %q, want %q", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTCPProberBanner(t *testing.T) {
+	tests := []struct {
+		name   string
+		serve  func(net.Conn)
+		probe  []byte
+		max    int
+		banner string
+	}{
+		{
+			name:   "greeting",
+			serve:  func(c net.Conn) { c.Write([]byte("220 mail ready\r\n")) },
+			max:    256,
+			banner: "220 mail ready",
+		},
+		{
+			name: "answers the probe",
+			serve: func(c net.Conn) {
+				line, _ := bufio.NewReader(c).ReadString('\n')
+				c.Write([]byte("echo " + line))
+			},
+			probe:  []byte("hello\n"),
+			max:    256,
+			banner: "echo hello",
+		},
+		{
+			name:   "silent without a probe",
+			serve:  func(c net.Conn) { time.Sleep(time.Second) },
+			max:    256,
+			banner: "",
+		},
+		{
+			name:   "truncated",
+			serve:  func(c net.Conn) { c.Write([]byte("0123456789")) },
+			max:    4,
+			banner: "0123",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewTCPProber(time.Second)
+			p.Banner = &BannerOptions{
+				ReadTimeout: 100 * time.Millisecond,
+				Probe:       tt.probe,
+				MaxBytes:    tt.max,
+			}
+
+			r := p.Probe(context.Background(), Task{IP: "127.0.0.1", Port: listenTCP(t, tt.serve)})
+			if r.Status != result.StatusOpen {
+				t.Fatalf("status = %s (%s), want open", r.Status, r.Error)
+			}
+			if r.Banner != tt.banner {
+				t.Errorf("banner = %q, want %q", r.Banner, tt.banner)
+			}
+		})
+	}
+}
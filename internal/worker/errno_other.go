@@ -0,0 +1,23 @@
+//go:build !windows
+
+package worker
+
+import "syscall"
+
+// Socket errors that decide a probe's status
+var (
+	errConnRefused = syscall.ECONNREFUSED
+	errConnReset   = syscall.ECONNRESET
+	errHostUnreach = syscall.EHOSTUNREACH
+	errNetUnreach  = syscall.ENETUNREACH
+)
+
+// localErrnos are the errors of a host out of descriptors, buffers or
+// ephemeral ports
+var localErrnos = []error{
+	syscall.EMFILE,
+	syscall.ENFILE,
+	syscall.ENOBUFS,
+	syscall.ENOMEM,
+	syscall.EADDRNOTAVAIL,
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package worker
+
+import "syscall"
+
+// Socket errors that decide a probe's status. Winsock reports its own
+// WSA error codes rather than the POSIX values in package syscall.
+var (
+	errConnRefused = syscall.Errno(10061) // WSAECONNREFUSED
+	errConnReset   = syscall.Errno(10054) // WSAECONNRESET
+	errHostUnreach = syscall.Errno(10065) // WSAEHOSTUNREACH
+	errNetUnreach  = syscall.Errno(10051) // WSAENETUNREACH
+)
+
+// localErrnos are the errors of a host out of descriptors, buffers or
+// ephemeral ports
+var localErrnos = []error{
+	syscall.Errno(10024), // WSAEMFILE
+	syscall.Errno(10055), // WSAENOBUFS
+	syscall.Errno(10049), // WSAEADDRNOTAVAIL
+}
@@ -0,0 +1,52 @@
+package worker
+
+import (
+	"errors"
+	"net"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+)
+
+// ClassifyError maps the error of a failed TCP probe to the port's status
+// and the reason for it. Refusals and resets mean the port is closed;
+// timeouts and ICMP unreachables mean something filtered the probe;
+// anything else is an error rather than evidence about the port.
+func ClassifyError(err error) (result.Status, string) {
+	var dnsErr *net.DNSError
+
+	switch {
+	case isTimeout(err):
+		return result.StatusFiltered, result.ReasonNoResponse
+	case errors.Is(err, errConnRefused):
+		return result.StatusClosed, result.ReasonConnRefused
+	case errors.Is(err, errConnReset):
+		return result.StatusClosed, result.ReasonReset
+	case errors.Is(err, errHostUnreach):
+		return result.StatusFiltered, result.ReasonHostUnreach
+	case errors.Is(err, errNetUnreach):
+		return result.StatusFiltered, result.ReasonNetUnreach
+	case isLocalError(err):
+		return result.StatusError, result.ReasonLocalError
+	case errors.As(err, &dnsErr):
+		return result.StatusError, result.ReasonDNSError
+	default:
+		return result.StatusError, result.ReasonError
+	}
+}
+
+// isLocalError reports whether err comes from exhausting a resource of
+// this host, so the probe may succeed if retried later
+func isLocalError(err error) bool {
+	for _, errno := range localErrnos {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTimeout reports whether err is a network timeout
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
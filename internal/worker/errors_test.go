@@ -0,0 +1,88 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+)
+
+// dialError wraps an errno the way a failed dial reports it
+func dialError(errno error) error {
+	return &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", errno)}
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status result.Status
+		reason string
+	}{
+		{"refused", dialError(errConnRefused), result.StatusClosed, result.ReasonConnRefused},
+		{"reset", dialError(errConnReset), result.StatusClosed, result.ReasonReset},
+		{"host unreachable", dialError(errHostUnreach), result.StatusFiltered, result.ReasonHostUnreach},
+		{"network unreachable", dialError(errNetUnreach), result.StatusFiltered, result.ReasonNetUnreach},
+		{"timeout", &net.OpError{Op: "dial", Err: context.DeadlineExceeded}, result.StatusFiltered, result.ReasonNoResponse},
+		{"out of descriptors", dialError(localErrnos[0]), result.StatusError, result.ReasonLocalError},
+		{"DNS", &net.DNSError{Err: "no such host", Name: "db01.test"}, result.StatusError, result.ReasonDNSError},
+		{"other", errors.New("something else"), result.StatusError, result.ReasonError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, reason := ClassifyError(tt.err)
+			if status != tt.status || reason != tt.reason {
+				t.Errorf("ClassifyError(%v) = %s (%s), want %s (%s)", tt.err, status, reason, tt.status, tt.reason)
+			}
+		})
+	}
+}
+
+// closedTCPPort returns a loopback TCP port with nothing listening on it
+func closedTCPPort(t *testing.T) int {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	return port
+}
+
+func TestTCPProber(t *testing.T) {
+	tests := []struct {
+		name   string
+		port   func(t *testing.T) int
+		status result.Status
+		reason string
+	}{
+		{
+			name:   "listening",
+			port:   func(t *testing.T) int { return listenTCP(t, func(net.Conn) {}) },
+			status: result.StatusOpen,
+			reason: result.ReasonSynAck,
+		},
+		{
+			name:   "refused",
+			port:   closedTCPPort,
+			status: result.StatusClosed,
+			reason: result.ReasonConnRefused,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewTCPProber(time.Second).Probe(context.Background(), Task{IP: "127.0.0.1", Port: tt.port(t)})
+			if r.Status != tt.status || r.Reason != tt.reason {
+				t.Errorf("got %s (%s), want %s (%s)", r.Status, r.Reason, tt.status, tt.reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+)
+
+// Prober probes a single task and reports its outcome
+type Prober interface {
+	Probe(ctx context.Context, task Task) *result.Result
+}
+
+// TCPProber performs a full TCP connect scan
+type TCPProber struct {
+	Timeout time.Duration
+
+	// RTT, when set, replaces the fixed Timeout with one adapted to each
+	// host's measured round-trip time
+	RTT *RTTEstimator
+
+	// Banner enables banner grabbing on open ports when set
+	Banner *BannerOptions
+}
+
+// NewTCPProber creates a new TCP connect prober
+func NewTCPProber(timeout time.Duration) *TCPProber {
+	return &TCPProber{
+		Timeout: timeout,
+	}
+}
+
+// Probe attempts a TCP connection to the task's address
+func (p *TCPProber) Probe(ctx context.Context, task Task) *result.Result {
+	startTime := time.Now()
+
+	r := &result.Result{
+		IP:        task.IP,
+		Hostname:  task.Hostname,
+		Port:      task.Port,
+		Protocol:  "tcp",
+		Timestamp: startTime,
+	}
+
+	address := net.JoinHostPort(task.IP, strconv.Itoa(task.Port))
+
+	timeout := p.Timeout
+	if p.RTT != nil {
+		timeout = p.RTT.Timeout(task.IP, task.Attempts)
+	}
+
+	// Attempt TCP connection with timeout
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+
+	r.Duration = time.Since(startTime)
+
+	// Accepted and refused connections both took one round trip
+	if p.RTT != nil && (err == nil || errors.Is(err, errConnRefused)) {
+		p.RTT.Observe(task.IP, r.Duration)
+	}
+
+	if err != nil {
+		// Tell closed and filtered ports apart from failures on our side
+		r.Status, r.Reason = ClassifyError(err)
+		r.Error = err.Error()
+	} else {
+		r.Status, r.Reason = result.StatusOpen, result.ReasonSynAck
+		if p.Banner != nil {
+			r.Banner = grabBanner(conn, p.Banner)
+		}
+		conn.Close()
+	}
+
+	return r
+}
+
+// Enricher adds detail to results for ports found open, such as service
+// fingerprints or protocol metadata
+type Enricher interface {
+	Enrich(ctx context.Context, r *result.Result)
+}
+
+// EnrichingProber runs enrichers against open results of an underlying prober
+type EnrichingProber struct {
+	Prober    Prober
+	Enrichers []Enricher
+}
+
+// NewEnrichingProber wraps a prober with a chain of enrichers
+func NewEnrichingProber(prober Prober, enrichers ...Enricher) *EnrichingProber {
+	return &EnrichingProber{
+		Prober:    prober,
+		Enrichers: enrichers,
+	}
+}
+
+// Probe runs the underlying probe and enriches the result if the port is open
+func (p *EnrichingProber) Probe(ctx context.Context, task Task) *result.Result {
+	r := p.Prober.Probe(ctx, task)
+	if r.Status != result.StatusOpen {
+		return r
+	}
+
+	for _, e := range p.Enrichers {
+		if ctx.Err() != nil {
+			break
+		}
+		e.Enrich(ctx, r)
+	}
+
+	return r
+}
@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+)
+
+// maxBackoffShift caps how many times the retry delay doubles
+const maxBackoffShift = 10
+
+// RetryPolicy decides which probes are tried again and when
+type RetryPolicy struct {
+	// Retries is the number of attempts allowed after the first
+	Retries int
+
+	// Backoff is the delay before the first retry; it doubles for each
+	// further one
+	Backoff time.Duration
+
+	// Limiter, when set, is the scan's rate limiter. Retries wait for its
+	// ticks like fresh probes do, so together they stay within the rate.
+	Limiter <-chan time.Time
+}
+
+// Retry reports whether a probe should be tried again. Only timeouts and
+// local resource errors qualify: a dropped packet or a full file table
+// can clear on a second attempt, while a reset or an unreachable host will
+// not. Silent UDP ports are not retried either, as most never answer and
+// another attempt would only repeat open|filtered.
+func (p RetryPolicy) Retry(r *result.Result) bool {
+	if r.Attempts > p.Retries || r.Status == result.StatusOpenFiltered {
+		return false
+	}
+	return r.Reason == result.ReasonNoResponse || r.Reason == result.ReasonLocalError
+}
+
+// Pace waits until the rate limiter allows another probe. It reports false
+// if the context ends first.
+func (p RetryPolicy) Pace(ctx context.Context) bool {
+	if p.Limiter == nil {
+		return true
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-p.Limiter:
+		return true
+	}
+}
+
+// Delay returns how long to wait before the next attempt of a probe tried
+// attempts times so far. The exponential backoff is jittered by up to half
+// either way, so retries of probes that failed together spread out.
+func (p RetryPolicy) Delay(attempts int) time.Duration {
+	d := p.Backoff << min(max(attempts-1, 0), maxBackoffShift)
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + rand.N(d)
+}
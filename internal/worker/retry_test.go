@@ -0,0 +1,164 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+)
+
+func TestRetryPolicyRetry(t *testing.T) {
+	policy := RetryPolicy{Retries: 2}
+
+	tests := []struct {
+		name     string
+		attempts int
+		status   result.Status
+		reason   string
+		want     bool
+	}{
+		{"timed out", 1, result.StatusFiltered, result.ReasonNoResponse, true},
+		{"local error", 1, result.StatusError, result.ReasonLocalError, true},
+		{"last retry", 2, result.StatusFiltered, result.ReasonNoResponse, true},
+		{"retries used up", 3, result.StatusFiltered, result.ReasonNoResponse, false},
+		{"silent UDP port", 1, result.StatusOpenFiltered, result.ReasonNoResponse, false},
+		{"open", 1, result.StatusOpen, result.ReasonSynAck, false},
+		{"refused", 1, result.StatusClosed, result.ReasonConnRefused, false},
+		{"reset", 1, result.StatusClosed, result.ReasonReset, false},
+		{"unreachable", 1, result.StatusFiltered, result.ReasonHostUnreach, false},
+		{"DNS error", 1, result.StatusError, result.ReasonDNSError, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &result.Result{Status: tt.status, Reason: tt.reason, Attempts: tt.attempts}
+			if got := policy.Retry(r); got != tt.want {
+				t.Errorf("Retry(%s (%s), attempts %d) = %v, want %v", tt.status, tt.reason, tt.attempts, got, tt.want)
+			}
+		})
+	}
+
+	if (RetryPolicy{}).Retry(&result.Result{Status: result.StatusFiltered, Reason: result.ReasonNoResponse, Attempts: 1}) {
+		t.Error("a policy without retries retried")
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	tests := []struct {
+		backoff  time.Duration
+		attempts int
+		base     time.Duration
+	}{
+		{100 * time.Millisecond, 1, 100 * time.Millisecond},
+		{100 * time.Millisecond, 2, 200 * time.Millisecond},
+		{100 * time.Millisecond, 4, 800 * time.Millisecond},
+		{time.Millisecond, 11, 1024 * time.Millisecond},
+		{time.Millisecond, 50, 1024 * time.Millisecond}, // doubling is capped
+		{time.Millisecond, 0, time.Millisecond},
+		{0, 3, 0},
+	}
+
+	for _, tt := range tests {
+		policy := RetryPolicy{Backoff: tt.backoff}
+		// The jitter is random, so sample it a few times
+		for range 50 {
+			d := policy.Delay(tt.attempts)
+			if tt.base == 0 {
+				if d != 0 {
+					t.Fatalf("Delay(%d) with no backoff = %v, want 0", tt.attempts, d)
+				}
+				continue
+			}
+			if d < tt.base/2 || d >= tt.base*3/2 {
+				t.Fatalf("Delay(%d) with backoff %v = %v, want within [%v, %v)", tt.attempts, tt.backoff, d, tt.base/2, tt.base*3/2)
+			}
+		}
+	}
+}
+
+func TestRetryPolicyPace(t *testing.T) {
+	if !(RetryPolicy{}).Pace(context.Background()) {
+		t.Error("Pace without a limiter = false, want true")
+	}
+
+	ticks := make(chan time.Time, 1)
+	ticks <- time.Now()
+	policy := RetryPolicy{Limiter: ticks}
+	if !policy.Pace(context.Background()) {
+		t.Error("Pace with a tick ready = false, want true")
+	}
+
+	// No tick left: only the context can end the wait
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if policy.Pace(ctx) {
+		t.Error("Pace with a cancelled context = true, want false")
+	}
+}
+
+// flakyProber times out on each port a set number of times before
+// reporting it open
+type flakyProber struct {
+	mu       sync.Mutex
+	failures map[int]int
+}
+
+func (f *flakyProber) Probe(ctx context.Context, task Task) *result.Result {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	r := &result.Result{IP: task.IP, Port: task.Port, Status: result.StatusOpen, Reason: result.ReasonSynAck}
+	if f.failures[task.Port] > 0 {
+		f.failures[task.Port]--
+		r.Status, r.Reason = result.StatusFiltered, result.ReasonNoResponse
+	}
+	return r
+}
+
+func TestPoolRetries(t *testing.T) {
+	tests := []struct {
+		port     int
+		failures int
+		status   result.Status
+		attempts int
+	}{
+		{1, 0, result.StatusOpen, 1},
+		{2, 1, result.StatusOpen, 2},
+		{3, 2, result.StatusOpen, 3},
+		{4, 3, result.StatusFiltered, 3},
+	}
+
+	prober := &flakyProber{failures: make(map[int]int)}
+	for _, tt := range tests {
+		prober.failures[tt.port] = tt.failures
+	}
+
+	results := make(chan *result.Result, len(tests))
+	pool := NewPool(2, results, prober)
+	pool.Retry = RetryPolicy{Retries: 2, Backoff: time.Millisecond}
+	pool.Start(context.Background())
+	for _, tt := range tests {
+		if err := pool.Submit(context.Background(), Task{IP: "10.0.0.1", Port: tt.port}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pool.Close()
+	close(results)
+
+	byPort := make(map[int]*result.Result)
+	for r := range results {
+		byPort[r.Port] = r
+	}
+	for _, tt := range tests {
+		r := byPort[tt.port]
+		if r == nil {
+			t.Errorf("port %d: no result", tt.port)
+			continue
+		}
+		if r.Status != tt.status || r.Attempts != tt.attempts {
+			t.Errorf("port %d: got %s after %d attempts, want %s after %d", tt.port, r.Status, r.Attempts, tt.status, tt.attempts)
+		}
+	}
+}
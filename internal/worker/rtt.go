@@ -0,0 +1,81 @@
+package worker
+
+import (
+	"sync"
+	"time"
+)
+
+// rttState is the smoothed round-trip time of one host and its variation
+type rttState struct {
+	srtt, rttvar time.Duration
+}
+
+// RTTEstimator derives probe timeouts from the round-trip times measured to
+// each host, the way RFC 6298 derives TCP's retransmission timeout: the
+// timeout is the smoothed RTT plus four times its mean deviation, kept
+// within [Min, Max]. Hosts not yet measured get the Initial timeout, kept
+// within the same bounds. Each retry of a probe doubles its timeout, up to
+// Max.
+type RTTEstimator struct {
+	Initial time.Duration
+	Min     time.Duration
+	Max     time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*rttState
+}
+
+// NewRTTEstimator creates an estimator with the given timeout bounds
+func NewRTTEstimator(initial, minTimeout, maxTimeout time.Duration) *RTTEstimator {
+	return &RTTEstimator{
+		Initial: initial,
+		Min:     minTimeout,
+		Max:     maxTimeout,
+		hosts:   make(map[string]*rttState),
+	}
+}
+
+// Observe records a round-trip time measured to a host, such as how long a
+// connection took to be accepted or refused
+func (e *RTTEstimator) Observe(host string, rtt time.Duration) {
+	if rtt <= 0 {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s, ok := e.hosts[host]
+	if !ok {
+		e.hosts[host] = &rttState{srtt: rtt, rttvar: rtt / 2}
+		return
+	}
+
+	// RTTVAR <- 3/4 RTTVAR + 1/4 |SRTT - R'|, then SRTT <- 7/8 SRTT + 1/8 R'
+	delta := s.srtt - rtt
+	if delta < 0 {
+		delta = -delta
+	}
+	s.rttvar = s.rttvar*3/4 + delta/4
+	s.srtt = s.srtt*7/8 + rtt/8
+}
+
+// Timeout returns how long to wait for a reply from a host to a probe that
+// has been tried attempts times before
+func (e *RTTEstimator) Timeout(host string, attempts int) time.Duration {
+	e.mu.Lock()
+	s, ok := e.hosts[host]
+	timeout := min(max(e.Initial, e.Min), e.Max)
+	if ok {
+		timeout = max(s.srtt+4*s.rttvar, e.Min)
+	}
+	e.mu.Unlock()
+
+	for range min(attempts, maxBackoffShift) {
+		if timeout >= e.Max {
+			break
+		}
+		timeout *= 2
+	}
+	return min(timeout, e.Max)
+}
@@ -0,0 +1,84 @@
+package worker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRTTEstimatorInitial(t *testing.T) {
+	tests := []struct {
+		name    string
+		initial time.Duration
+		want    time.Duration
+	}{
+		{"within bounds", time.Second, time.Second},
+		{"below the minimum", 10 * time.Millisecond, 100 * time.Millisecond},
+		{"above the maximum", time.Minute, 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewRTTEstimator(tt.initial, 100*time.Millisecond, 5*time.Second)
+			if got := e.Timeout("10.0.0.1", 0); got != tt.want {
+				t.Errorf("Timeout = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRTTEstimatorObserve(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []time.Duration
+		want    time.Duration
+	}{
+		// The first sample sets SRTT = R and RTTVAR = R/2, so 3R
+		{"first sample", []time.Duration{100 * time.Millisecond}, 300 * time.Millisecond},
+		{"first sample below the minimum", []time.Duration{10 * time.Millisecond}, 50 * time.Millisecond},
+		// SRTT = 7/8·100 + 1/8·100, RTTVAR = 3/4·50 + 1/4·0
+		{"steady", []time.Duration{100 * time.Millisecond, 100 * time.Millisecond}, 100*time.Millisecond + 4*37500*time.Microsecond},
+		// SRTT = 7/8·100 + 1/8·200 = 112.5, RTTVAR = 3/4·50 + 1/4·100 = 62.5
+		{"slower", []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, 112500*time.Microsecond + 4*62500*time.Microsecond},
+		{"non-positive ignored", []time.Duration{100 * time.Millisecond, 0, -time.Second}, 300 * time.Millisecond},
+		{"only non-positive", []time.Duration{0}, time.Second},
+		{"capped", []time.Duration{3 * time.Second}, 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewRTTEstimator(time.Second, 50*time.Millisecond, 5*time.Second)
+			for _, rtt := range tt.samples {
+				e.Observe("10.0.0.1", rtt)
+			}
+			if got := e.Timeout("10.0.0.1", 0); got != tt.want {
+				t.Errorf("Timeout = %v, want %v", got, tt.want)
+			}
+			if got := e.Timeout("10.0.0.2", 0); got != time.Second {
+				t.Errorf("unmeasured host's Timeout = %v, want the initial %v", got, time.Second)
+			}
+		})
+	}
+}
+
+func TestRTTEstimatorBackoff(t *testing.T) {
+	e := NewRTTEstimator(time.Second, 100*time.Millisecond, 5*time.Second)
+	e.Observe("10.0.0.1", 100*time.Millisecond)
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 300 * time.Millisecond},
+		{1, 600 * time.Millisecond},
+		{2, 1200 * time.Millisecond},
+		{4, 4800 * time.Millisecond},
+		{5, 5 * time.Second},
+		{100, 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := e.Timeout("10.0.0.1", tt.attempts); got != tt.want {
+			t.Errorf("Timeout after %d attempts = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
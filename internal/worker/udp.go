@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+)
+
+// UDPProber sends a protocol-aware datagram and classifies the reply.
+// A reply means open, an ICMP port-unreachable (reported as ECONNREFUSED
+// on a connected socket) means closed, and silence means open|filtered.
+type UDPProber struct {
+	Timeout time.Duration
+}
+
+// NewUDPProber creates a new UDP prober
+func NewUDPProber(timeout time.Duration) *UDPProber {
+	return &UDPProber{
+		Timeout: timeout,
+	}
+}
+
+// Probe sends the payload registered for the task's port and waits for a reply
+func (p *UDPProber) Probe(ctx context.Context, task Task) *result.Result {
+	startTime := time.Now()
+
+	r := &result.Result{
+		IP:        task.IP,
+		Hostname:  task.Hostname,
+		Port:      task.Port,
+		Protocol:  "udp",
+		Timestamp: startTime,
+	}
+
+	address := net.JoinHostPort(task.IP, strconv.Itoa(task.Port))
+
+	// Connect the socket so ICMP errors are reported back to us
+	dialer := net.Dialer{Timeout: p.Timeout}
+	conn, err := dialer.DialContext(ctx, "udp", address)
+	if err != nil {
+		r.Duration = time.Since(startTime)
+		r.Status, r.Reason = ClassifyError(err)
+		r.Error = err.Error()
+		return r
+	}
+	defer conn.Close()
+
+	deadline := startTime.Add(p.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+
+	// Unblock the read if the scan is cancelled
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	_, err = conn.Write(UDPPayload(task.Port))
+	if err == nil {
+		buf := make([]byte, 1500)
+		_, err = conn.Read(buf)
+	}
+
+	r.Duration = time.Since(startTime)
+
+	switch {
+	case err == nil:
+		r.Status, r.Reason = result.StatusOpen, result.ReasonUDPResponse
+	case errors.Is(err, errConnRefused):
+		r.Status, r.Reason = result.StatusClosed, result.ReasonPortUnreach
+		r.Error = err.Error()
+	case isTimeout(err):
+		r.Status, r.Reason = result.StatusOpenFiltered, result.ReasonNoResponse
+	default:
+		r.Status, r.Reason = ClassifyError(err)
+		r.Error = err.Error()
+	}
+
+	return r
+}
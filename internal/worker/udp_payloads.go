@@ -0,0 +1,127 @@
+package worker
+
+import "encoding/binary"
+
+// udpPayloads maps well-known UDP ports to a datagram that elicits a reply
+// from the service listening there. Services stay silent on malformed or
+// empty input, so a protocol-specific payload is the only way to tell an
+// open port from a filtered one.
+var udpPayloads = map[int][]byte{
+	// DNS: standard query for the root NS records
+	53: {
+		0x13, 0x37, 0x01, 0x00, 0x00, 0x01, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x00,
+		0x01,
+	},
+
+	// NTP: version 4 client request
+	123: append([]byte{0xe3}, make([]byte, 47)...),
+
+	// NetBIOS: node status request for the wildcard name
+	137: append(append([]byte{
+		0x13, 0x37, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x20, 0x43, 0x4b,
+	}, repeat('A', 30)...), 0x00, 0x00, 0x21, 0x00, 0x01),
+
+	// SNMP: v1 get-request for sysDescr.0 with community "public"
+	161: {
+		0x30, 0x29, 0x02, 0x01, 0x00, 0x04, 0x06, 'p',
+		'u', 'b', 'l', 'i', 'c', 0xa0, 0x1c, 0x02,
+		0x04, 0x13, 0x37, 0x13, 0x37, 0x02, 0x01, 0x00,
+		0x02, 0x01, 0x00, 0x30, 0x0e, 0x30, 0x0c, 0x06,
+		0x08, 0x2b, 0x06, 0x01, 0x02, 0x01, 0x01, 0x01,
+		0x00, 0x05, 0x00,
+	},
+
+	// IKE: main mode proposal offering 3DES/SHA1/PSK/MODP-1024
+	500: ikeMainModeProposal(),
+
+	// Syslog: services rarely answer, but a well-formed message is harmless
+	514: []byte("<14>netscout: probe\n"),
+
+	// SSDP: discovery request for all devices
+	1900: []byte("M-SEARCH * HTTP/1.1\r\n" +
+		"HOST: 239.255.255.250:1900\r\n" +
+		"MAN: \"ssdp:discover\"\r\n" +
+		"MX: 1\r\n" +
+		"ST: ssdp:all\r\n\r\n"),
+
+	// mDNS: PTR query for the DNS-SD service enumeration name
+	5353: append(append([]byte{
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00,
+	}, dnsName("_services", "_dns-sd", "_udp", "local")...), 0x00, 0x0c, 0x00, 0x01),
+}
+
+// UDPPayload returns the probe datagram for a port, or an empty datagram
+// when no protocol-specific payload is known
+func UDPPayload(port int) []byte {
+	if payload, ok := udpPayloads[port]; ok {
+		return payload
+	}
+	return []byte{}
+}
+
+// ikeMainModeProposal builds an ISAKMP main mode packet carrying a single
+// security association proposal
+func ikeMainModeProposal() []byte {
+	// Transform attributes (type/value pairs, basic format)
+	attrs := []byte{
+		0x80, 0x01, 0x00, 0x05, // encryption: 3DES-CBC
+		0x80, 0x02, 0x00, 0x02, // hash: SHA1
+		0x80, 0x03, 0x00, 0x01, // auth: pre-shared key
+		0x80, 0x04, 0x00, 0x02, // group: MODP-1024
+		0x80, 0x0b, 0x00, 0x01, // life type: seconds
+		0x00, 0x0c, 0x00, 0x04, 0x00, 0x00, 0x70, 0x80, // life duration: 28800
+	}
+
+	transform := []byte{0x00, 0x00, 0x00, 0x00, 0x01, 0x01, 0x00, 0x00}
+	transform = append(transform, attrs...)
+	binary.BigEndian.PutUint16(transform[2:], uint16(len(transform)))
+
+	proposal := []byte{0x00, 0x00, 0x00, 0x00, 0x01, 0x01, 0x00, 0x01}
+	proposal = append(proposal, transform...)
+	binary.BigEndian.PutUint16(proposal[2:], uint16(len(proposal)))
+
+	sa := []byte{
+		0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x01, // DOI: IPsec
+		0x00, 0x00, 0x00, 0x01, // situation: identity only
+	}
+	sa = append(sa, proposal...)
+	binary.BigEndian.PutUint16(sa[2:], uint16(len(sa)))
+
+	header := []byte{
+		0x13, 0x37, 0x13, 0x37, 0x13, 0x37, 0x13, 0x37, // initiator cookie
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // responder cookie
+		0x01,                   // next payload: SA
+		0x10,                   // version 1.0
+		0x02,                   // exchange: identity protection
+		0x00,                   // flags
+		0x00, 0x00, 0x00, 0x00, // message ID
+		0x00, 0x00, 0x00, 0x00, // length
+	}
+	packet := append(header, sa...)
+	binary.BigEndian.PutUint32(packet[24:], uint32(len(packet)))
+
+	return packet
+}
+
+// dnsName encodes labels in DNS wire format
+func dnsName(labels ...string) []byte {
+	var name []byte
+	for _, label := range labels {
+		name = append(name, byte(len(label)))
+		name = append(name, label...)
+	}
+	return append(name, 0x00)
+}
+
+// repeat returns a slice containing b repeated n times
+func repeat(b byte, n int) []byte {
+	out := make([]byte, n)
+	for i := range out {
+		out[i] = b
+	}
+	return out
+}
@@ -0,0 +1,111 @@
+package worker
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/JeffreyOmoakah/netscout.git/internal/result"
+)
+
+// listenUDP starts a loopback UDP listener that answers each datagram when
+// reply is set and stays silent otherwise. It returns the listener's port.
+func listenUDP(t *testing.T, reply bool) int {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if reply {
+				conn.WriteTo(buf[:n], addr)
+			}
+		}
+	}()
+
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+// closedUDPPort returns a loopback UDP port with nothing listening on it
+func closedUDPPort(t *testing.T) int {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+	conn.Close()
+	return port
+}
+
+func TestUDPProber(t *testing.T) {
+	tests := []struct {
+		name   string
+		port   func(t *testing.T) int
+		status result.Status
+		reason string
+	}{
+		{
+			name:   "reply",
+			port:   func(t *testing.T) int { return listenUDP(t, true) },
+			status: result.StatusOpen,
+			reason: result.ReasonUDPResponse,
+		},
+		{
+			name:   "silent",
+			port:   func(t *testing.T) int { return listenUDP(t, false) },
+			status: result.StatusOpenFiltered,
+			reason: result.ReasonNoResponse,
+		},
+		{
+			name:   "port unreachable",
+			port:   closedUDPPort,
+			status: result.StatusClosed,
+			reason: result.ReasonPortUnreach,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewUDPProber(200 * time.Millisecond)
+			r := p.Probe(context.Background(), Task{IP: "127.0.0.1", Port: tt.port(t)})
+
+			if r.Status != tt.status || r.Reason != tt.reason {
+				t.Errorf("got %s (%s), want %s (%s)", r.Status, r.Reason, tt.status, tt.reason)
+			}
+			if r.Protocol != "udp" {
+				t.Errorf("protocol = %q, want udp", r.Protocol)
+			}
+		})
+	}
+}
+
+func TestUDPPayload(t *testing.T) {
+	tests := []struct {
+		port  int
+		empty bool
+	}{
+		{port: 53},
+		{port: 123},
+		{port: 161},
+		{port: 500},
+		{port: 9, empty: true},
+	}
+
+	for _, tt := range tests {
+		if got := len(UDPPayload(tt.port)) == 0; got != tt.empty {
+			t.Errorf("UDPPayload(%d) empty = %v, want %v", tt.port, got, tt.empty)
+		}
+	}
+}
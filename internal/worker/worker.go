@@ -2,8 +2,7 @@ package worker
 
 import (
 	"context"
-	"net"
-	"strconv"
+	"sync"
 	"time"
 
 	"github.com/JeffreyOmoakah/netscout.git/internal/result"
@@ -11,88 +10,104 @@ import (
 
 // Task represents a single scan task
 type Task struct {
-	IP   string
-	Port int
+	IP       string
+	Hostname string
+	Port     int
+
+	// Seq is the task's position in the scan's task stream
+	Seq uint64
+
+	// Attempts counts the probes already made for the task
+	Attempts int
 }
 
-// Worker performs TCP port scanning
+// Worker runs probes for tasks received from the pool
 type Worker struct {
-	id         int
-	taskChan   <-chan Task
-	resultChan chan<- *result.Result
-	timeout    time.Duration
+	id   int
+	pool *Pool
 }
 
-// NewWorker creates a new worker
-func NewWorker(id int, taskChan <-chan Task, resultChan chan<- *result.Result, timeout time.Duration) *Worker {
+// NewWorker creates a new worker for a pool
+func NewWorker(id int, pool *Pool) *Worker {
 	return &Worker{
-		id:         id,
-		taskChan:   taskChan,
-		resultChan: resultChan,
-		timeout:    timeout,
+		id:   id,
+		pool: pool,
 	}
 }
 
-// Start begins the worker's task processing loop
+// Start begins the worker's task processing loop. It takes fresh tasks and
+// due retries alike until the pool is closed and has nothing left to retry.
 func (w *Worker) Start(ctx context.Context) {
+	tasks := w.pool.taskChan
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case task, ok := <-w.taskChan:
+		case <-w.pool.stop:
+			return
+		case task := <-w.pool.retryChan:
+			w.scan(ctx, task)
+		case task, ok := <-tasks:
 			if !ok {
-				return
+				// Keep serving retries
+				tasks = nil
+				continue
 			}
-			w.scan(task)
+			w.scan(ctx, task)
 		}
 	}
 }
 
-// scan performs a TCP connect scan on a single target
-func (w *Worker) scan(task Task) {
-	startTime := time.Now()
-	
-	r := &result.Result{
-		IP:        task.IP,
-		Port:      task.Port,
-		Timestamp: startTime,
+// scan probes a single target and forwards the result, or schedules the
+// task to be tried again when the pool's retry policy allows it
+func (w *Worker) scan(ctx context.Context, task Task) {
+	r := w.pool.prober.Probe(ctx, task)
+	r.Seq = task.Seq
+	r.Attempts = task.Attempts + 1
+
+	// Probes interrupted by cancellation carry no useful information
+	if ctx.Err() != nil {
+		w.pool.finish()
+		return
 	}
 
-	address := net.JoinHostPort(task.IP, strconv.Itoa(task.Port))
-
-	
-	// Attempt TCP connection with timeout
-	conn, err := net.DialTimeout("tcp", address, w.timeout)
-	
-	r.Duration = time.Since(startTime)
-
-	if err != nil {
-		// Determine if port is filtered or closed based on error type
-		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-			r.Status = result.StatusFiltered
-		} else {
-			r.Status = result.StatusClosed
-		}
-		r.Error = err.Error()
-	} else {
-		r.Status = result.StatusOpen
-		conn.Close()
+	if w.pool.Retry.Retry(r) {
+		task.Attempts = r.Attempts
+		w.pool.retryLater(ctx, task, w.pool.Retry.Delay(task.Attempts))
+		return
 	}
 
-	w.resultChan <- r
+	w.pool.resultChan <- r
+	w.pool.finish()
 }
 
 // Pool manages a pool of workers
 type Pool struct {
 	workers    []*Worker
 	taskChan   chan Task
+	retryChan  chan Task
 	resultChan chan<- *result.Result
-	timeout    time.Duration
+	prober     Prober
 	size       int
+	wg         sync.WaitGroup
+
+	// Retry decides which failed probes are tried again; the zero value
+	// tries each task once
+	Retry RetryPolicy
+
+	// active counts tasks submitted but not yet reported, including those
+	// waiting to be retried. idle is closed once the pool is closed and
+	// active drops to zero, and stop then tells the workers to exit.
+	mu     sync.Mutex
+	active int
+	closed bool
+	idle   chan struct{}
+	stop   chan struct{}
+	done   <-chan struct{}
 }
 
-// NewPool creates a new worker pool
-func NewPool(size int, resultChan chan<- *result.Result, timeout time.Duration) *Pool {
+// NewPool creates a new worker pool that runs tasks through the given prober
+func NewPool(size int, resultChan chan<- *result.Result, prober Prober) *Pool {
 	// Buffer size should be large enough to hold many tasks
 	// but not so large it consumes too much memory
 	bufferSize := size * 10
@@ -103,37 +118,88 @@ func NewPool(size int, resultChan chan<- *result.Result, timeout time.Duration)
 	return &Pool{
 		workers:    make([]*Worker, 0, size),
 		taskChan:   make(chan Task, bufferSize),
+		retryChan:  make(chan Task),
 		resultChan: resultChan,
-		timeout:    timeout,
+		prober:     prober,
 		size:       size,
+		idle:       make(chan struct{}),
+		stop:       make(chan struct{}),
 	}
 }
 
 // Start initializes and starts all workers in the pool
 func (p *Pool) Start(ctx context.Context) {
+	p.done = ctx.Done()
 	for i := 0; i < p.size; i++ {
-		worker := NewWorker(i, p.taskChan, p.resultChan, p.timeout)
+		worker := NewWorker(i, p)
 		p.workers = append(p.workers, worker)
-		go worker.Start(ctx)
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			worker.Start(ctx)
+		}()
 	}
 }
 
 // Submit submits a task to the worker pool
 func (p *Pool) Submit(ctx context.Context, task Task) error {
+	p.mu.Lock()
+	p.active++
+	p.mu.Unlock()
+
 	select {
 	case <-ctx.Done():
+		p.finish()
 		return ctx.Err()
 	case p.taskChan <- task:
 		return nil
 	}
 }
 
-// Close closes the task channel and waits for all workers to finish
+// Close closes the task channel and waits for all workers to finish,
+// including any retries still pending. A cancelled scan abandons them.
 func (p *Pool) Close() {
 	close(p.taskChan)
+
+	p.mu.Lock()
+	p.closed = true
+	if p.active == 0 {
+		close(p.idle)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-p.idle:
+	case <-p.done:
+	}
+	close(p.stop)
+	p.wg.Wait()
+}
+
+// retryLater hands a task back to the workers once its backoff delay has
+// passed and the rate limit allows. The wait holds no worker, so fresh
+// tasks keep flowing meanwhile.
+func (p *Pool) retryLater(ctx context.Context, task Task, delay time.Duration) {
+	time.AfterFunc(delay, func() {
+		if !p.Retry.Pace(ctx) {
+			p.finish()
+			return
+		}
+		select {
+		case p.retryChan <- task:
+		case <-ctx.Done():
+			p.finish()
+		}
+	})
 }
 
-// GetTaskChannel returns the task channel (useful for direct access)
-func (p *Pool) GetTaskChannel() chan<- Task {
-	return p.taskChan
-}
\ No newline at end of file
+// finish records that a task has been reported or abandoned
+func (p *Pool) finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.active--
+	if p.active == 0 && p.closed {
+		close(p.idle)
+	}
+}